@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"maps"
+	"slices"
+	"text/template"
+
+	"github.com/twelvelabs/termite/render"
+
+	"github.com/twelvelabs/schemadoc/internal/core"
+	"github.com/twelvelabs/schemadoc/internal/format"
+)
+
+// RegisterFuncs adds funcs to the funcs available to templates,
+// replacing any with the same name. It must be called before
+// the templates are parsed (i.e. before running a command).
+func RegisterFuncs(funcs template.FuncMap) {
+	maps.Copy(render.FuncMap, funcs)
+}
+
+// registerConfigFuncs registers the external command funcs in the config.
+// Commands run in the dir of the config file.
+func registerConfigFuncs(app *core.App) error {
+	dir := app.Config.ResolvePath(".")
+	for _, name := range slices.Sorted(maps.Keys(app.Config.Funcs)) {
+		fn, err := format.CommandFunc(name, app.Config.Funcs[name].Command, dir)
+		if err != nil {
+			return err
+		}
+		if _, ok := render.FuncMap[name]; ok {
+			app.Logger.Warn("Template func overrides a built-in func", "name", name)
+		}
+		RegisterFuncs(template.FuncMap{name: fn})
+	}
+	return nil
+}
@@ -5,29 +5,42 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"text/template"
 	"time"
 
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/creasty/defaults"
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/twelvelabs/termite/fsutil"
 	"github.com/twelvelabs/termite/render"
 	"github.com/twelvelabs/termite/validate"
 	stripmd "github.com/writeas/go-strip-markdown"
 
 	"github.com/twelvelabs/schemadoc/internal/core"
+	"github.com/twelvelabs/schemadoc/internal/format"
 	"github.com/twelvelabs/schemadoc/internal/jsonschema"
 	"github.com/twelvelabs/schemadoc/internal/markdown"
 )
 
 func init() {
-	render.FuncMap["toHTML"] = markdown.ToHTMLString
-	render.FuncMap["wrapCode"] = markdown.WrapCode
-	render.FuncMap["firstSentence"] = markdown.FirstSentence
-	render.FuncMap["stripMarkdown"] = stripmd.Strip
+	RegisterFuncs(template.FuncMap{
+		"toHTML":        markdown.ToHTMLString,
+		"wrapCode":      markdown.WrapCode,
+		"firstSentence": markdown.FirstSentence,
+		"stripMarkdown": stripmd.Strip,
+	})
+	RegisterFuncs(format.FuncMap())
 }
 
 func NewGenCmd(app *core.App) *cobra.Command {
@@ -48,80 +61,676 @@ func NewGenCmd(app *core.App) *cobra.Command {
 	}
 
 	flags := cmd.Flags()
-	flags.StringVarP(&a.InPath, "in", "i", a.InPath, "file path or dir to one or more JSON schema files")
+	a.addInputFlags(flags)
 	flags.StringVarP(&a.OutDir, "out", "o", a.OutDir, "output dir to generate files to")
-	flags.StringVarP(&a.OutFile, "outfile", "f", a.OutFile, "custom filename pattern for generated files")
-	flags.StringVarP(&a.TemplatePath, "template", "t", a.TemplatePath, "custom template path")
+	flags.StringVar(&a.Format, "format", a.Format, fmt.Sprintf("output format (%s)", strings.Join(format.Names(), ", ")))
+	flags.StringVar(&a.Preset, "preset", a.Preset, fmt.Sprintf("static site generator preset (%s)", strings.Join(format.PresetNames(), ", ")))
+	flags.StringVar(&a.PresetBase, "preset-base", a.PresetBase, "path of the output dir within the site's docs dir (prefixed to the paths in the preset navigation)")
+	flags.BoolVar(&a.Single, "single", a.Single, "generate a single file documenting every entity (with a table of contents)")
+	flags.BoolVar(&a.Index, "index", a.Index, "generate an index page listing every entity")
+	flags.StringVar(&a.IndexTemplatePath, "index-template", a.IndexTemplatePath, "custom index template path (implies --index)")
+	flags.BoolVar(&a.Check, "check", a.Check, "check that generated files are up to date (without writing them)")
+	flags.BoolVar(&a.Prune, "prune", a.Prune, "remove previously generated files that are no longer generated")
+	flags.BoolVarP(&a.Watch, "watch", "w", a.Watch, "watch schemas and templates, regenerating docs on change")
+	flags.StringVar(&a.Target, "target", a.Target, "only generate the named target in the config file")
+	a.flags = flags
 
 	return cmd
 }
 
+// addInputFlags adds the flags shared by the commands
+// that load and render schemas.
+func (a *GenAction) addInputFlags(flags *pflag.FlagSet) {
+	flags.StringArrayVarP(&a.InPaths, "in", "i", a.InPaths, "file path or dir to one or more JSON schema files (repeatable)")
+	flags.StringArrayVar(&a.Includes, "include", a.Includes, "glob pattern of schema files to include from dirs (repeatable)")
+	flags.StringArrayVar(&a.Excludes, "exclude", a.Excludes, "glob pattern of files or dirs to exclude from dirs (repeatable)")
+	flags.StringVarP(&a.OutFile, "outfile", "f", a.OutFile, "custom filename pattern for generated files")
+	flags.StringVarP(&a.TemplatePath, "template", "t", a.TemplatePath, "custom template path (or dir of partials overriding the built-in templates)")
+	flags.StringToStringVar(&a.Mappings, "map", a.Mappings, "rewrite a schema URI prefix to a local path (uri=path)")
+	flags.StringSliceVar(&a.Catalogs, "catalog", a.Catalogs, "SchemaStore-style catalog used to resolve schemas")
+}
+
 type GenAction struct {
 	*core.App
 
-	InPath       string `validate:"required"`
-	OutDir       string `validate:"required" default:"out"`
-	OutFile      string `validate:"required" default:"{{ .EntityName | underscore }}.md"`
-	OutFileTpl   render.Template
-	SchemaPaths  []string
-	TemplatePath string
+	Catalogs          []string
+	Check             bool
+	Excludes          []string
+	Format            string   `validate:"required" default:"markdown"`
+	InPaths           []string `validate:"required,min=1"`
+	InMemory          bool     // Render docs in-memory only (i.e. for `serve`).
+	Includes          []string
+	Index             bool
+	IndexTemplatePath string
+	Mappings          map[string]string
+	OutDir            string `validate:"required" default:"out"`
+	OutFile           string
+	OutFileTpl        render.Template
+	Preset            string
+	PresetBase        string
+	Prune             bool
+	Registry          *jsonschema.Registry
+	SchemaDirs        map[string]string
+	SchemaInputDirs   map[string]string
+	SchemaPaths       []string
+	Single            bool
+	Target            string
+	TemplatePath      string
+	Watch             bool
+
+	catalogs []*jsonschema.Catalog
+	// flags are the command flags (used to tell set flags from defaults).
+	flags  *pflag.FlagSet
+	format *format.Format
+	preset *format.Preset
+	// templateDir is true if TemplatePath is a dir of partials.
+	templateDir bool
 }
 
-func (a *GenAction) Run(_ context.Context, _ []string) error {
+const (
+	// DefaultInclude is used when no include patterns are configured.
+	DefaultInclude = "**/*.schema.json"
+	// SingleContentsTitle is the title of the table of contents in single file mode.
+	SingleContentsTitle = "Contents"
+	// StdioPath is the `--in` (or `--out`) value for stdin (or stdout).
+	StdioPath = "-"
+	// StdoutSeparator is the text of the comment preceding each document
+	// when several are written to stdout. The verb is the generated file path.
+	StdoutSeparator = "schemadoc: %s"
+)
+
+// foundSchema is a schema found in one of the inputs.
+type foundSchema struct {
+	// Location is the path or URI of the schema.
+	Location string
+	// Dir is the dir of the schema relative to the input dir.
+	Dir string
+	// InputDir is the (absolute) input dir of a local schema
+	// (i.e. the dir of a file input). Empty if the schema is not local.
+	InputDir string
+}
+
+func (a *GenAction) Run(ctx context.Context, _ []string) error {
+	if err := registerConfigFuncs(a.App); err != nil {
+		return fmt.Errorf("funcs config: %w", err)
+	}
+	if a.Target != "" || (len(a.InPaths) == 0 && len(a.Config.Targets) > 0) {
+		return a.runTargets(ctx)
+	}
+	return a.run(ctx)
+}
+
+// run generates the docs for the inputs.
+func (a *GenAction) run(ctx context.Context) error {
 	if err := a.setup(); err != nil {
 		return err
 	}
+	if a.Watch {
+		return a.watch(ctx, a.generate)
+	}
+	_, err := a.generate(nil)
+	return err
+}
+
+// Doc is a document to be generated for an entity.
+type Doc struct {
+	// Entity is the schema documented by the doc.
+	Entity *jsonschema.Schema
+	// Path is the slash-separated path of the doc, relative to the output dir.
+	Path string
+	// Deps are the local files the doc depends on.
+	Deps []string
+}
 
+// build loads the schemas and returns a doc for each entity.
+func (a *GenAction) build() ([]*Doc, error) {
+	// Share a single context across all inputs so that schemas referenced
+	// from several inputs are only loaded (and generated) once.
+	context := jsonschema.NewContext(jsonschema.WithRegistry(a.Registry))
+	roots := []*jsonschema.Schema{}
 	for _, path := range a.SchemaPaths {
-		path, err := filepath.Abs(path)
+		schema, err := context.Get(path)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		// Keep the input dir structure in the output dir.
+		schema.GenDir = a.SchemaDirs[path]
+		schema.SourceDir = a.SchemaInputDirs[path]
+		roots = append(roots, schema)
+	}
 
-		context := jsonschema.NewContext()
-		schema, err := context.Get(path)
+	entities := jsonschema.NewIndex(roots...).Entities()
+	if len(roots) > 0 {
+		// Documents that are not inputs (i.e. loaded via a `$ref`)
+		// are reported relative to the first input.
+		for _, entity := range entities {
+			if doc := entity.DocumentRoot(); doc.SourceDir == "" {
+				doc.SourceDir = roots[0].SourceDir
+			}
+		}
+	}
+	genPathTpl := a.OutFileTpl
+	if a.Single && len(entities) > 0 {
+		// Every entity is documented in the file named for the first.
+		path, err := a.OutFileTpl.Render(entities[0])
 		if err != nil {
-			return err
+			return nil, err
+		}
+		tpl, err := render.Compile(path)
+		if err != nil {
+			return nil, err
+		}
+		genPathTpl = *tpl
+	}
+	for _, entity := range entities {
+		entity.Root().GenPathTpl = genPathTpl
+		if a.Single {
+			entity.Root().GenDir = ""
+		}
+	}
+	docs := []*Doc{}
+	// The URI of the entity using each output path
+	// (or anchor, as entities are sections in single file mode).
+	used := map[string]string{}
+	if a.Single {
+		reserveAnchor(strings.ToLower(SingleContentsTitle), used)
+	}
+	for _, entity := range entities {
+		path := filepath.ToSlash(entity.GenPath())
+		key := path
+		if a.Single {
+			key = entity.EntityAnchor()
+		}
+		if uri, ok := used[key]; ok {
+			if uri == entity.EntityURI() {
+				// i.e. the same entity found via two inputs.
+				continue
+			}
+			if a.Single {
+				// Distinct entities with the same anchor (e.g. the same title),
+				// or an entity named after a property heading (which is expected).
+				renamed := uniqueAnchor(key, used)
+				log := a.Logger.Warn
+				if uri == "" {
+					log = a.Logger.Debug
+				}
+				log("Renaming colliding anchor", "anchor", key, "renamed", renamed, "entity", entity.EntityURI())
+				entity.GenAnchor = renamed
+				key = renamed
+			} else {
+				// Distinct entities with the same path (e.g. the same title).
+				renamed := uniquePath(path, used)
+				a.Logger.Warn("Renaming colliding output path", "path", path, "renamed", renamed, "entity", entity.EntityURI())
+				entity.GenPathName = renamed
+				path, key = renamed, renamed
+			}
 		}
-		schema.GenPathTpl = a.OutFileTpl
+		used[key] = entity.EntityURI()
+		if a.Single {
+			reserveSectionAnchors(entity, used)
+		}
+		docs = append(docs, &Doc{
+			Entity: entity,
+			Path:   path,
+			Deps:   a.dependencies(entity),
+		})
+	}
+	return docs, nil
+}
 
-		if err := a.generateSchema(schema); err != nil {
-			return err
+// uniqueAnchor returns anchor with the first numeric suffix (i.e. `foo-1`)
+// that is not in used. Matches the IDs Markdown renderers give repeated
+// headings, as Markdown headings have no explicit anchors.
+func uniqueAnchor(anchor string, used map[string]string) string {
+	for n := 1; ; n++ {
+		candidate := fmt.Sprintf("%s-%d", anchor, n)
+		if _, ok := used[candidate]; !ok {
+			return candidate
 		}
-		for _, subSchema := range schema.Definitions {
-			if err := a.generateSchema(subSchema); err != nil {
-				return err
+	}
+}
+
+// reserveSectionAnchors adds the anchors of the headings rendered
+// in the section of entity (after its own) to used, in the order
+// the built-in templates render them. Entities rendered later that
+// share them (i.e. the type of a property named after it) are then renamed
+// rather than linked to the property heading.
+func reserveSectionAnchors(entity *jsonschema.Schema, used map[string]string) {
+	if len(entity.OneOf) > 0 {
+		reserveAnchor("variants", used)
+	}
+	if len(entity.Properties) > 0 {
+		reserveAnchor("properties", used)
+	}
+	for _, key := range slices.Sorted(maps.Keys(entity.Properties)) {
+		reserveAnchor(strings.ReplaceAll(strings.ToLower(key), " ", "-"), used)
+	}
+}
+
+// reserveAnchor adds the anchor of a heading that is not an entity
+// to used, renamed as Markdown renderers do when repeated.
+func reserveAnchor(anchor string, used map[string]string) {
+	if _, ok := used[anchor]; ok {
+		anchor = uniqueAnchor(anchor, used)
+	}
+	used[anchor] = ""
+}
+
+// uniquePath returns p with the first numeric suffix (i.e. `foo-2.md`)
+// that is not in used.
+func uniquePath(p string, used map[string]string) string {
+	ext := path.Ext(p)
+	base := strings.TrimSuffix(p, ext)
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s-%d%s", base, n, ext)
+		if _, ok := used[candidate]; !ok {
+			return candidate
+		}
+	}
+}
+
+// generate generates the docs for each entity.
+// When affected is not nil, only the docs that depend on
+// one of the affected files are (re)generated.
+// Returns the local files that the docs depend on.
+func (a *GenAction) generate(affected map[string]bool) ([]string, error) {
+	docs, err := a.build()
+	if err != nil {
+		return nil, err
+	}
+
+	var layout *htmlLayout
+	if a.format.HTMLLayout {
+		if layout, err = newHTMLLayout(a.htmlTemplateDir()); err != nil {
+			return nil, err
+		}
+		// Every page links to every other page (in the sidebar).
+		affected = nil
+	}
+
+	var pages []format.Page
+	if a.preset != nil {
+		pages = presetPages(docs)
+	}
+
+	var paths []string
+	var stale int
+	if a.Single {
+		paths, stale, err = a.outputSingle(docs, layout)
+		if len(pages) > 0 {
+			pages = pages[:1]
+		}
+	} else {
+		paths, stale, err = a.outputDocs(docs, pages, layout, affected)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if layout != nil && a.OutDir != StdioPath {
+		style, err := htmlStyle(a.htmlTemplateDir())
+		if err != nil {
+			return nil, err
+		}
+		ok, err := a.output(HTMLStyleName, string(style), 0, 1)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			stale++
+		}
+		paths = append(paths, HTMLStyleName)
+	}
+
+	if a.preset != nil && a.OutDir != StdioPath {
+		files, err := a.preset.Nav(pages, siteTitle(a.OutDir), a.PresetBase)
+		if err != nil {
+			return nil, err
+		}
+		for idx, file := range files {
+			ok, err := a.output(file.Path, file.Content, idx, len(files))
+			if err != nil {
+				return nil, err
 			}
+			if !ok {
+				stale++
+			}
+			paths = append(paths, file.Path)
 		}
 	}
 
-	return nil
+	if a.OutDir != StdioPath {
+		pruned, err := a.updateManifest(paths)
+		if err != nil {
+			return nil, fmt.Errorf("manifest: %w", err)
+		}
+		stale += pruned
+	}
+
+	if stale > 0 {
+		return nil, fmt.Errorf("%d generated file(s) are out of date", stale)
+	}
+	return docDeps(docs), nil
+}
+
+// outputDocs outputs a file per doc (and the index, if enabled).
+// Returns the output paths and the number of stale files.
+func (a *GenAction) outputDocs(
+	docs []*Doc, pages []format.Page, layout *htmlLayout, affected map[string]bool,
+) ([]string, int, error) {
+	// The index (if any) is written last.
+	total := len(docs)
+	indexPath := a.format.IndexPath()
+	if a.Index {
+		if idx := slices.IndexFunc(docs, func(doc *Doc) bool { return doc.Path == indexPath }); idx >= 0 {
+			return nil, 0, fmt.Errorf("index: %s is also generated for %s", indexPath, docs[idx].Entity.EntityURI())
+		}
+		total++
+	}
+	if a.OutDir == StdioPath && !a.Check && total > 1 && a.format.Comment == "" {
+		return nil, 0, fmt.Errorf(
+			"'--out': can not write %d documents to stdout (the %s format has no comments to separate them)",
+			total, a.format.Name,
+		)
+	}
+
+	stale := 0
+	for idx, doc := range docs {
+		if affected != nil && !slices.ContainsFunc(doc.Deps, func(dep string) bool { return affected[dep] }) {
+			continue
+		}
+
+		rendered, err := a.renderSchema(doc.Entity)
+		if err != nil {
+			return nil, 0, err
+		}
+		var page format.Page
+		if pages != nil {
+			page = pages[idx]
+		}
+		if rendered, err = a.decorate(rendered, doc, docs, layout, page); err != nil {
+			return nil, 0, err
+		}
+		ok, err := a.output(doc.Path, rendered, idx, total)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !ok {
+			stale++
+		}
+	}
+
+	paths := docPaths(docs)
+	if a.Index {
+		index := a.newIndex(siteTitle(a.OutDir), false, docs, func(doc *Doc) string {
+			return jsonschema.RelativePath(indexPath, doc.Path)
+		})
+		rendered, err := a.renderIndex(index)
+		if err != nil {
+			return nil, 0, err
+		}
+		page := format.Page{Path: indexPath, Title: index.Title}
+		if rendered, err = a.decorate(rendered, nil, docs, layout, page); err != nil {
+			return nil, 0, err
+		}
+		ok, err := a.output(indexPath, rendered, total-1, total)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !ok {
+			stale++
+		}
+		paths = append(paths, indexPath)
+	}
+	return paths, stale, nil
+}
+
+// outputSingle outputs every doc to a single file (the path of the first doc),
+// preceded by a table of contents.
+// Returns the output paths and the number of stale files.
+func (a *GenAction) outputSingle(docs []*Doc, layout *htmlLayout) ([]string, int, error) {
+	if len(docs) == 0 {
+		return nil, 0, nil
+	}
+	path := docs[0].Path
+
+	// Not grouped, since group headings would clash with the anchors of root entities.
+	index := a.newIndex(SingleContentsTitle, true, docs, func(doc *Doc) string {
+		return "#" + doc.Entity.EntityAnchor()
+	})
+	toc, err := a.renderIndex(index)
+	if err != nil {
+		return nil, 0, err
+	}
+	sections := []string{strings.TrimRight(toc, "\n")}
+	for _, doc := range docs {
+		rendered, err := a.renderSchema(doc.Entity)
+		if err != nil {
+			return nil, 0, err
+		}
+		sections = append(sections, strings.TrimRight(rendered, "\n"))
+	}
+	rendered := strings.Join(sections, "\n\n") + "\n"
+
+	// Not a doc, since the page documents every entity.
+	page := presetPages(docs)[0]
+	if rendered, err = a.decorate(rendered, nil, docs, layout, page); err != nil {
+		return nil, 0, err
+	}
+	ok, err := a.output(path, rendered, 0, 1)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !ok {
+		return []string{path}, 1, nil
+	}
+	return []string{path}, 0, nil
+}
+
+// decorate wraps rendered in the HTML layout (if any) and prefixes
+// the preset front matter (if any). Doc is the doc being
+// rendered, or nil for pages that are not the doc of an entity.
+func (a *GenAction) decorate(
+	rendered string, doc *Doc, docs []*Doc, layout *htmlLayout, page format.Page,
+) (string, error) {
+	var err error
+	if layout != nil {
+		htmlPage, err := newHTMLPage(doc, docs, a.htmlIndexPage(), rendered)
+		if err != nil {
+			return "", err
+		}
+		if doc == nil && page.Title != "" {
+			htmlPage.Title = page.Title
+		}
+		if a.Single {
+			// Every entity is on this page.
+			for idx, other := range docs {
+				htmlPage.Nav[idx].Link = "#" + other.Entity.EntityAnchor()
+			}
+		}
+		if rendered, err = layout.Render(htmlPage); err != nil {
+			return "", err
+		}
+	}
+	if a.preset != nil {
+		if rendered, err = a.preset.Render(page, rendered); err != nil {
+			return "", err
+		}
+	}
+	return rendered, nil
+}
+
+// htmlIndexPage returns the index page linked from the HTML
+// navigation, or nil if there is none.
+func (a *GenAction) htmlIndexPage() *format.Page {
+	if !a.Index || a.Single {
+		return nil
+	}
+	return &format.Page{Path: a.format.IndexPath(), Title: siteTitle(a.OutDir)}
+}
+
+// output checks or writes the rendered content for path (the doc at idx
+// of total). Returns false if checking and the file is out of date.
+func (a *GenAction) output(path string, rendered string, idx int, total int) (bool, error) {
+	switch {
+	case a.Check:
+		return a.checkFile(path, rendered)
+	case a.OutDir == StdioPath:
+		return true, a.writeStdout(path, rendered, idx, total)
+	default:
+		if err := a.writeFile(path, rendered); err != nil {
+			return false, err
+		}
+		a.Logger.Info("Generated", "path", filepath.Join(a.OutDir, path))
+		return true, nil
+	}
+}
+
+// docPaths returns the paths of docs.
+func docPaths(docs []*Doc) []string {
+	paths := []string{}
+	for _, doc := range docs {
+		paths = append(paths, doc.Path)
+	}
+	return paths
+}
+
+// docDeps returns the (unique) dependencies of docs.
+func docDeps(docs []*Doc) []string {
+	deps := []string{}
+	for _, doc := range docs {
+		deps = append(deps, doc.Deps...)
+	}
+	slices.Sort(deps)
+	return slices.Compact(deps)
+}
+
+// updateManifest records the generated files in the output dir manifest.
+// When pruning, files from earlier runs that were not generated
+// this time are removed (in check mode, they are only reported
+// and their count is returned).
+func (a *GenAction) updateManifest(generated []string) (int, error) {
+	manifest, err := ReadManifest(a.OutDir)
+	if errors.Is(err, ErrInvalidManifest) {
+		// Nothing can be pruned, but the manifest is rewritten below.
+		a.Logger.Warn("Ignoring invalid manifest", "err", err)
+		manifest, err = &Manifest{Version: ManifestVersion}, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	stale := manifest.Stale(generated)
+
+	if a.Check {
+		if !a.Prune {
+			return 0, nil
+		}
+		for _, file := range stale {
+			fmt.Fprintf(a.IO.Out, "would remove %s\n", filepath.Join(a.OutDir, file))
+		}
+		return len(stale), nil
+	}
+
+	if a.Prune {
+		for _, file := range stale {
+			if err := removeFile(a.OutDir, file); err != nil {
+				return 0, err
+			}
+			a.Logger.Info("Pruned", "path", filepath.Join(a.OutDir, file))
+		}
+		stale = nil
+	}
+
+	// Keep tracking unpruned files, so that a later run can prune them.
+	stale = slices.DeleteFunc(stale, func(file string) bool {
+		_, err := os.Stat(filepath.Join(a.OutDir, filepath.FromSlash(file)))
+		return err != nil
+	})
+	manifest.Files = slices.Concat(generated, stale)
+	manifest.Version = ManifestVersion
+	return 0, manifest.Write(a.OutDir)
 }
 
-func (a *GenAction) generateSchema(schema *jsonschema.Schema) error {
+// renderSchema renders the template for schema.
+func (a *GenAction) renderSchema(schema *jsonschema.Schema) (string, error) {
 	var rendered string
 	var err error
 
-	if a.TemplatePath != "" {
+	if a.TemplatePath != "" && !a.templateDir {
 		rendered, err = render.File(a.TemplatePath, schema)
 	} else {
 		var tpl *template.Template
-		templatePath := "templates/markdown.tpl.md"
-		tpl, err = template.New(filepath.Base(templatePath)).
-			Funcs(render.FuncMap).
-			ParseFS(jsonschema.Templates, templatePath)
+		tpl, err = a.parseTemplate(a.format.Parse, a.format.Override)
 		if err != nil {
-			return err
+			return "", err
 		}
 		buf := bytes.Buffer{}
 		err = tpl.Execute(&buf, schema)
 		rendered = buf.String()
 	}
+	return rendered, err
+}
+
+// newIndex returns an index of docs, linked to with link.
+func (a *GenAction) newIndex(title string, contents bool, docs []*Doc, link func(doc *Doc) string) *format.Index {
+	index := &format.Index{
+		Version:  format.ModelVersion,
+		Title:    title,
+		Contents: contents,
+	}
+	for _, doc := range docs {
+		index.Add(doc.Entity, link(doc))
+	}
+	return index
+}
+
+// renderIndex renders the index page for index.
+func (a *GenAction) renderIndex(index *format.Index) (string, error) {
+	if a.IndexTemplatePath != "" {
+		return render.File(a.IndexTemplatePath, index)
+	}
+	tpl, err := a.parseTemplate(a.format.ParseIndex, a.format.OverrideIndex)
 	if err != nil {
-		return err
+		return "", err
 	}
+	buf := bytes.Buffer{}
+	err = tpl.Execute(&buf, index)
+	return buf.String(), err
+}
+
+// parseTemplate parses a built-in template with parse, overridden
+// (with override) by the templates in the `--template` dir (if any).
+func (a *GenAction) parseTemplate(
+	parse func(template.FuncMap) (*template.Template, error),
+	override func(*template.Template, string) error,
+) (*template.Template, error) {
+	tpl, err := parse(render.FuncMap)
+	if err != nil {
+		return nil, err
+	}
+	if a.templateDir {
+		if err := override(tpl, a.TemplatePath); err != nil {
+			return nil, fmt.Errorf("template: %w", err)
+		}
+	}
+	return tpl, nil
+}
+
+// htmlTemplateDir returns the `--template` dir (if any),
+// which may also override the HTML layout and stylesheet.
+func (a *GenAction) htmlTemplateDir() string {
+	if a.templateDir {
+		return a.TemplatePath
+	}
+	return ""
+}
 
-	outPath := filepath.Join(a.OutDir, schema.GenPath())
+// writeFile writes rendered to genPath within the output dir.
+func (a *GenAction) writeFile(genPath string, rendered string) error {
+	outPath := filepath.Join(a.OutDir, genPath)
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil { //nolint: gosec
+		return err
+	}
 	if err := os.WriteFile(outPath, []byte(rendered), 0644); err != nil { //nolint: gosec
 		return err
 	}
@@ -129,37 +738,204 @@ func (a *GenAction) generateSchema(schema *jsonschema.Schema) error {
 	return nil
 }
 
+// checkFile compares rendered with the content of genPath within
+// the output dir. If they differ, it prints a unified diff
+// (from the file on disk to rendered) and returns false.
+func (a *GenAction) checkFile(genPath string, rendered string) (bool, error) {
+	outPath := filepath.Join(a.OutDir, genPath)
+	fromFile := outPath
+	current, err := os.ReadFile(outPath) //nolint: gosec
+	if errors.Is(err, fs.ErrNotExist) {
+		fromFile = os.DevNull
+	} else if err != nil {
+		return false, err
+	}
+	if string(current) == rendered {
+		return true, nil
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(string(current)),
+		B:        splitLines(rendered),
+		FromFile: fromFile,
+		ToFile:   outPath,
+		Context:  3,
+	})
+	if err != nil {
+		return false, err
+	}
+	_, err = fmt.Fprint(a.IO.Out, diff)
+	return false, err
+}
+
+// splitLines splits s into newline terminated lines.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	for idx := range lines {
+		lines[idx] += "\n"
+	}
+	return lines
+}
+
+// writeStdout writes rendered (the document at idx of total) to stdout.
+// When there are several documents, each one is preceded by a comment
+// (in the syntax of the format) containing the path it would have been
+// generated to.
+func (a *GenAction) writeStdout(genPath string, rendered string, idx int, total int) error {
+	out := rendered
+	if total > 1 {
+		out = fmt.Sprintf(a.format.Comment, fmt.Sprintf(StdoutSeparator, genPath)) + "\n\n" + rendered
+		if idx < total-1 && !strings.HasSuffix(out, "\n\n") {
+			out = strings.TrimSuffix(out, "\n") + "\n\n"
+		}
+	}
+	_, err := fmt.Fprint(a.IO.Out, out)
+	return err
+}
+
+// readStdin reads a schema from stdin and stores it in-memory
+// under a path in the working dir, so that relative refs
+// resolve as they would for a file in that dir.
+func (a *GenAction) readStdin() (string, error) {
+	doc, err := io.ReadAll(a.IO.In)
+	if err != nil {
+		return "", err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	location := filepath.Join(cwd, StdioPath)
+
+	loader := jsonschema.NewMemoryLoader(a.Registry.RegisteredLoader(""))
+	loader.Store(location, doc)
+	a.Registry.RegisterLoader(loader, "")
+	return location, nil
+}
+
 func (a *GenAction) setup() error {
 	start := time.Now()
 
 	if err := validate.Struct(a); err != nil {
 		msg := err.Error()
-		msg = strings.ReplaceAll(msg, "InPath", `'--in'`)
+		msg = strings.ReplaceAll(msg, "InPaths", `'--in'`)
 		msg = strings.ReplaceAll(msg, "OutDir", `'--out'`)
 		msg = strings.ReplaceAll(msg, "OutFile", `'--outfile'`)
+		msg = strings.ReplaceAll(msg, "Format", `'--format'`)
 		msg = strings.ReplaceAll(msg, "field", "flag")
 		return errors.New(msg)
 	}
 
-	info, err := os.Stat(a.InPath)
-	if err != nil {
-		return fmt.Errorf(`'--in': %w`, err)
+	// Use a dedicated registry so mappings don't leak into other contexts.
+	// Register catalogs first so that explicit mappings take precedence.
+	a.Registry = jsonschema.NewDefaultRegistry()
+	if err := a.setupHTTP(); err != nil {
+		return fmt.Errorf("http config: %w", err)
+	}
+	if a.Watch && (a.Check || a.OutDir == StdioPath) {
+		return fmt.Errorf(`'--watch': can not be used with '--check' or stdout`)
+	}
+	if a.Watch || a.InMemory {
+		if slices.Contains(a.InPaths, StdioPath) {
+			return fmt.Errorf(`'--in': can not read stdin when watching for changes`)
+		}
+		// Always read the latest content of local files.
+		a.Registry.RegisterLoader(jsonschema.NewFileLoader(), "", "file")
+	}
+	a.catalogs = []*jsonschema.Catalog{}
+	// Config file catalogs are relative to the config file.
+	catalogs := []string{}
+	for _, path := range a.Config.Catalogs {
+		catalogs = append(catalogs, a.Config.ResolvePath(path))
 	}
-	if info.IsDir() {
-		pattern := filepath.Join(a.InPath, "*.schema.json")
-		matches, err := filepath.Glob(pattern)
+	for _, path := range slices.Concat(catalogs, a.Catalogs) {
+		path, err := filepath.Abs(path)
 		if err != nil {
-			return fmt.Errorf(`'--in': %w`, err)
+			return fmt.Errorf(`'--catalog': %w`, err)
 		}
-		a.SchemaPaths = matches
-	} else {
-		a.SchemaPaths = []string{a.InPath}
+		catalog, err := a.Registry.LoadCatalog(path)
+		if err != nil {
+			return fmt.Errorf(`'--catalog': %w`, err)
+		}
+		a.Registry.RegisterCatalog(catalog)
+		a.catalogs = append(a.catalogs, catalog)
+	}
+
+	// Mappings passed via flag take precedence over those in the config file.
+	// Config file targets are relative to the config file.
+	mappings := map[string]string{}
+	for prefix, target := range a.Config.Mappings {
+		mappings[prefix] = a.Config.ResolvePath(target)
+	}
+	maps.Copy(mappings, a.Mappings)
+	for prefix, target := range mappings {
+		a.Registry.RegisterMapping(prefix, target)
+	}
+	a.Mappings = mappings
+
+	// Include/exclude patterns passed via flag are added to those in the config file.
+	a.Includes = slices.Concat(a.Config.Include, a.Includes)
+	if len(a.Includes) == 0 {
+		a.Includes = []string{DefaultInclude}
+	}
+	a.Excludes = slices.Concat(a.Config.Exclude, a.Excludes)
+	for _, pattern := range a.Includes {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf(`'--include': invalid pattern %q`, pattern)
+		}
+	}
+	for _, pattern := range a.Excludes {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf(`'--exclude': invalid pattern %q`, pattern)
+		}
+	}
+
+	if err := a.discoverSchemas(); err != nil {
+		return fmt.Errorf(`'--in': %w`, err)
 	}
 
-	if err := fsutil.EnsureDirWritable(a.OutDir); err != nil {
-		return fmt.Errorf(`'--out': %w`, err)
+	if a.Check && a.OutDir == StdioPath {
+		return fmt.Errorf(`'--check': can not be used when writing to stdout`)
+	}
+	// Nothing is written in check mode, so the dir need not exist.
+	if a.OutDir != StdioPath && !a.Check && !a.InMemory {
+		if err := fsutil.EnsureDirWritable(a.OutDir); err != nil {
+			return fmt.Errorf(`'--out': %w`, err)
+		}
 	}
 
+	f, err := format.Lookup(a.Format)
+	if err != nil {
+		return fmt.Errorf(`'--format': %w`, err)
+	}
+	a.format = f
+	if a.Preset != "" {
+		if a.preset, err = format.LookupPreset(a.Preset); err != nil {
+			return fmt.Errorf(`'--preset': %w`, err)
+		}
+		if !a.preset.Supports(f.Name) {
+			return fmt.Errorf(
+				`'--preset': %s can not render the %s format (supported: %s)`,
+				a.preset.Name, f.Name, strings.Join(a.preset.Formats, ", "),
+			)
+		}
+	}
+	if a.PresetBase != "" {
+		if a.preset == nil || !a.preset.NavBase {
+			return fmt.Errorf(`'--preset-base': can only be used with the %s presets`, navBasePresets())
+		}
+		if !filepath.IsLocal(a.PresetBase) {
+			return fmt.Errorf(`'--preset-base': must be a relative path within the docs dir`)
+		}
+		a.PresetBase = path.Clean(filepath.ToSlash(a.PresetBase))
+	}
+	if a.OutFile == "" {
+		// Default to the format specific extension.
+		a.OutFile = a.format.DefaultOutFile()
+	}
 	tpl, err := render.Compile(a.OutFile)
 	if err != nil {
 		return fmt.Errorf(`'--outfile': %w`, err)
@@ -172,17 +948,262 @@ func (a *GenAction) setup() error {
 			return fmt.Errorf(`'--template': %w`, err)
 		}
 		if info.IsDir() {
-			return fmt.Errorf(`'--template': must not be a directory`)
+			// A dir of templates (or partials) overriding the built-in templates.
+			a.templateDir = true
+			tpl, err := a.format.Parse(render.FuncMap)
+			if err != nil {
+				return err
+			}
+			if err := a.format.Override(tpl, a.TemplatePath); err != nil {
+				return fmt.Errorf(`'--template': %w`, err)
+			}
+			index, err := a.format.ParseIndex(render.FuncMap)
+			if err != nil {
+				return err
+			}
+			if err := a.format.OverrideIndex(index, a.TemplatePath); err != nil {
+				return fmt.Errorf(`'--template': %w`, err)
+			}
+		}
+	}
+
+	if a.IndexTemplatePath != "" {
+		a.Index = true
+		info, err := os.Stat(a.IndexTemplatePath)
+		if err != nil {
+			return fmt.Errorf(`'--index-template': %w`, err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf(`'--index-template': must not be a directory`)
 		}
 	}
+	if a.Single {
+		if a.Index {
+			return fmt.Errorf(`'--index': can not be used with --single (which includes a table of contents)`)
+		}
+		if a.format.Name == format.JSON {
+			return fmt.Errorf(`'--single': can not be used with the json format`)
+		}
+	}
+	if a.Index && a.preset != nil && a.preset.NoIndex {
+		return fmt.Errorf(`'--index': can not be used with the %s preset (which generates its own index pages)`, a.preset.Name)
+	}
 
 	a.Logger.Debug(
 		"Setup",
 		"duration", time.Since(start),
+		"catalog", a.Catalogs,
+		"check", a.Check,
+		"exclude", a.Excludes,
+		"format", a.Format,
 		"in", a.SchemaPaths,
+		"include", a.Includes,
+		"map", a.Mappings,
 		"out", a.OutDir,
 		"outfile", a.OutFile,
+		"prune", a.Prune,
 		"template", a.TemplatePath,
 	)
 	return nil
 }
+
+// setupHTTP registers an HTTP loader configured from the app config.
+func (a *GenAction) setupHTTP() error {
+	config := a.Config.HTTP
+	client, err := jsonschema.NewHTTPClient(jsonschema.HTTPClientConfig{
+		CAFile:  config.CAFile,
+		Proxy:   config.Proxy,
+		Timeout: config.Timeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	opts := []jsonschema.HTTPLoaderOption{
+		jsonschema.WithRetries(config.Retries, 500*time.Millisecond),
+	}
+	for host, hostConfig := range config.Hosts {
+		opts = append(opts, jsonschema.WithHostHeaders(host, hostConfig.RequestHeaders()))
+	}
+
+	loader := jsonschema.NewHTTPLoader(client, opts...)
+	a.Registry.RegisterLoader(jsonschema.NewCachedLoader(loader), "http", "https")
+	return nil
+}
+
+// discoverSchemas finds the schemas in all inputs.
+func (a *GenAction) discoverSchemas() error {
+	a.SchemaDirs = map[string]string{}
+	a.SchemaInputDirs = map[string]string{}
+	a.SchemaPaths = []string{}
+	// The input each schema path (relative to its input dir) was found in.
+	inputs := map[string]string{}
+	for _, inPath := range a.InPaths {
+		var found []foundSchema
+		if inPath == StdioPath {
+			location, err := a.readStdin()
+			if err != nil {
+				return err
+			}
+			found = []foundSchema{{Location: location, InputDir: filepath.Dir(location)}}
+		} else {
+			var err error
+			found, err = a.findSchemas(inPath, a.catalogs)
+			if err != nil {
+				return err
+			}
+		}
+		for _, schema := range found {
+			if _, ok := a.SchemaDirs[schema.Location]; ok {
+				continue
+			}
+			if schema.Dir != "" {
+				// Dirs keep their structure in the output dir, so the same
+				// layout in two inputs would be generated to the same paths.
+				rel := path.Join(schema.Dir, path.Base(filepath.ToSlash(schema.Location)))
+				if other, ok := inputs[rel]; ok && other != inPath {
+					return fmt.Errorf("%s and %s both contain %s (which would be generated to the same path)", other, inPath, rel)
+				}
+				inputs[rel] = inPath
+			}
+			a.SchemaDirs[schema.Location] = schema.Dir
+			a.SchemaInputDirs[schema.Location] = schema.InputDir
+			a.SchemaPaths = append(a.SchemaPaths, schema.Location)
+		}
+	}
+	return nil
+}
+
+// findSchemas returns the schemas at location,
+// which may be a local file or dir, a file or dir within an archive
+// (or git revision), or the name or URL of a catalog entry.
+// Dirs are searched recursively using the include/exclude patterns.
+func (a *GenAction) findSchemas(location string, catalogs []*jsonschema.Catalog) ([]foundSchema, error) {
+	location, err := jsonschema.ExpandArchiveLocation(location)
+	if err != nil {
+		return nil, err
+	}
+	if u, err := url.Parse(location); err == nil && jsonschema.IsArchiveScheme(u.Scheme) {
+		// Archives are only readable once passed as an input.
+		loader, err := a.Registry.RegisterArchive(u)
+		if err != nil {
+			return nil, err
+		}
+		return a.findArchiveSchemas(loader, u)
+	}
+
+	info, err := os.Stat(location)
+	switch {
+	case err != nil:
+		// Not on disk, so see if it's the name or URL of a catalog entry.
+		resolved, ok := resolveCatalogs(catalogs, location)
+		if !ok {
+			return nil, err
+		}
+		return []foundSchema{{Location: resolved}}, nil
+	case info.IsDir():
+		dir, err := filepath.Abs(location)
+		if err != nil {
+			return nil, err
+		}
+		matches, err := a.matchSchemas(os.DirFS(dir), ".")
+		if err != nil {
+			return nil, err
+		}
+		found := []foundSchema{}
+		for _, match := range matches {
+			found = append(found, foundSchema{
+				Location: filepath.Join(dir, filepath.FromSlash(match)),
+				Dir:      path.Dir(match),
+				InputDir: dir,
+			})
+		}
+		return found, nil
+	default:
+		path, err := filepath.Abs(location)
+		if err != nil {
+			return nil, err
+		}
+		return []foundSchema{{Location: path, InputDir: filepath.Dir(path)}}, nil
+	}
+}
+
+// findArchiveSchemas returns the schemas at uri within an archive.
+func (a *GenAction) findArchiveSchemas(loader *jsonschema.ArchiveLoader, uri *url.URL) ([]foundSchema, error) {
+	fsys, name, err := loader.FS(uri)
+	if err != nil {
+		return nil, err
+	}
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []foundSchema{{Location: uri.String()}}, nil
+	}
+
+	matches, err := a.matchSchemas(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	archive, _, _ := strings.Cut(uri.Path, jsonschema.ArchiveSeparator)
+	found := []foundSchema{}
+	for _, match := range matches {
+		found = append(found, foundSchema{
+			Location: jsonschema.ArchiveURI(uri.Scheme, archive, path.Join(name, match)),
+			Dir:      path.Dir(match),
+		})
+	}
+	return found, nil
+}
+
+// matchSchemas walks dir in fsys and returns the paths (relative to dir)
+// of the files matching the include patterns, skipping any files
+// or dirs matching the exclude patterns.
+func (a *GenAction) matchSchemas(fsys fs.FS, dir string) ([]string, error) {
+	matches := []string{}
+	err := fs.WalkDir(fsys, dir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == dir {
+			return nil
+		}
+		rel := name
+		if dir != "." {
+			rel = strings.TrimPrefix(name, dir+"/")
+		}
+		if matchesAny(a.Excludes, rel) {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !entry.IsDir() && matchesAny(a.Includes, rel) {
+			matches = append(matches, rel)
+		}
+		return nil
+	})
+	return matches, err
+}
+
+// matchesAny returns true if name matches any of the glob patterns.
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := doublestar.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveCatalogs returns the location of the first catalog entry
+// matching nameOrURL.
+func resolveCatalogs(catalogs []*jsonschema.Catalog, nameOrURL string) (string, bool) {
+	for _, catalog := range catalogs {
+		if location, ok := catalog.Resolve(nameOrURL); ok {
+			return location, true
+		}
+	}
+	return "", false
+}
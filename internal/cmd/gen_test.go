@@ -0,0 +1,355 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/twelvelabs/schemadoc/internal/core"
+)
+
+// writeFiles writes the given files (relative to dir) with stub content.
+func writeFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(`{"type": "object"}`), 0600))
+	}
+}
+
+func TestGenAction_discoverSchemas(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir,
+		"a.schema.json",
+		"notes.json",
+		"v1/b.schema.json",
+		"vendor/c.schema.json",
+		"vendor/nested/d.schema.json",
+	)
+
+	tests := []struct {
+		name      string
+		includes  []string
+		excludes  []string
+		wantPaths []string
+		wantDirs  []string
+	}{
+		{
+			name:      "should find files matching the default include",
+			includes:  []string{DefaultInclude},
+			wantPaths: []string{"a.schema.json", "v1/b.schema.json", "vendor/c.schema.json", "vendor/nested/d.schema.json"},
+			wantDirs:  []string{".", "v1", "vendor", "vendor/nested"},
+		},
+		{
+			name:      "should skip excluded dirs",
+			includes:  []string{DefaultInclude},
+			excludes:  []string{"vendor/**"},
+			wantPaths: []string{"a.schema.json", "v1/b.schema.json"},
+			wantDirs:  []string{".", "v1"},
+		},
+		{
+			name:      "should match include patterns against relative paths",
+			includes:  []string{"*.json"},
+			wantPaths: []string{"a.schema.json", "notes.json"},
+			wantDirs:  []string{".", "."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &GenAction{
+				App:      core.NewTestApp(),
+				InPaths:  []string{dir},
+				Includes: tt.includes,
+				Excludes: tt.excludes,
+			}
+			require.NoError(t, a.discoverSchemas())
+
+			paths := []string{}
+			dirs := []string{}
+			for _, path := range a.SchemaPaths {
+				rel, err := filepath.Rel(dir, path)
+				require.NoError(t, err)
+				paths = append(paths, filepath.ToSlash(rel))
+				dirs = append(dirs, a.SchemaDirs[path])
+			}
+			require.Equal(t, tt.wantPaths, paths)
+			require.Equal(t, tt.wantDirs, dirs)
+		})
+	}
+}
+
+func TestGenAction_discoverSchemas_WhenFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "a.schema.json", "notes.json")
+
+	// Files are used as is (regardless of the include patterns),
+	// and are only loaded once.
+	a := &GenAction{
+		App: core.NewTestApp(),
+		InPaths: []string{
+			filepath.Join(dir, "notes.json"),
+			filepath.Join(dir, "notes.json"),
+		},
+		Includes: []string{DefaultInclude},
+	}
+	require.NoError(t, a.discoverSchemas())
+	require.Equal(t, []string{filepath.Join(dir, "notes.json")}, a.SchemaPaths)
+	require.Empty(t, a.SchemaDirs[a.SchemaPaths[0]])
+
+	a.InPaths = []string{filepath.Join(dir, "unknown.json")}
+	require.ErrorIs(t, a.discoverSchemas(), os.ErrNotExist)
+}
+
+func TestGenAction_discoverSchemas_WhenLayoutCollides(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir,
+		"one/v1/a.schema.json",
+		"two/v1/a.schema.json",
+		"two/v1/b.schema.json",
+	)
+
+	a := &GenAction{
+		App:      core.NewTestApp(),
+		InPaths:  []string{filepath.Join(dir, "one"), filepath.Join(dir, "two")},
+		Includes: []string{DefaultInclude},
+	}
+	err := a.discoverSchemas()
+	require.ErrorContains(t, err, "both contain v1/a.schema.json")
+
+	// Unless the colliding files are excluded.
+	a.Excludes = []string{"v1/a.schema.json"}
+	require.NoError(t, a.discoverSchemas())
+}
+
+func TestGenAction_build_WhenPathsCollide(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	schema := `{
+		"$id": "https://example.com/root.schema.json",
+		"title": "Root",
+		"type": "object",
+		"properties": {
+			"create": {"$ref": "#/definitions/CreateAction"},
+			"update": {"$ref": "#/definitions/UpdateAction"}
+		},
+		"definitions": {
+			"CreateAction": {"title": "Action", "type": "object"},
+			"UpdateAction": {"title": "Action", "type": "object"}
+		}
+	}`
+	require.NoError(os.WriteFile(filepath.Join(dir, "root.schema.json"), []byte(schema), 0600))
+
+	a := &GenAction{
+		App:     core.NewTestApp(),
+		Format:  "markdown",
+		InPaths: []string{filepath.Join(dir, "root.schema.json")},
+		OutDir:  filepath.Join(dir, "out"),
+	}
+	require.NoError(a.setup())
+	docs, err := a.build()
+	require.NoError(err)
+
+	// Distinct entities get distinct paths (and links follow them).
+	require.Len(docs, 3)
+	paths := map[string]string{}
+	for _, doc := range docs {
+		paths[doc.Entity.EntityURI()] = doc.Path
+	}
+	require.Equal(map[string]string{
+		"https://example.com/root.schema.json":                           "root.md",
+		"https://example.com/root.schema.json#/definitions/CreateAction": "action.md",
+		"https://example.com/root.schema.json#/definitions/UpdateAction": "action-2.md",
+	}, paths)
+	root := docs[0].Entity
+	require.Equal("action.md#action", root.Properties["create"].EntityLink())
+	require.Equal("action-2.md#action", root.Properties["update"].EntityLink())
+	// Sources are relative to the input dir.
+	require.Equal("root.schema.json", root.SourceURI())
+}
+
+func TestGenAction_build_WhenAnchorsCollide(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	// Entities with the same name in different schemas.
+	for _, name := range []string{"one", "two"} {
+		schema := `{
+			"$id": "https://example.com/` + name + `.schema.json",
+			"title": "` + name + `",
+			"type": "object",
+			"properties": {"action": {"$ref": "#/definitions/Action"}},
+			"definitions": {"Action": {"type": "object"}}
+		}`
+		require.NoError(os.WriteFile(filepath.Join(dir, name+".schema.json"), []byte(schema), 0600))
+	}
+
+	a := &GenAction{
+		App:      core.NewTestApp(),
+		Format:   "markdown",
+		InPaths:  []string{dir},
+		Includes: []string{DefaultInclude},
+		OutDir:   filepath.Join(dir, "out"),
+		Single:   true,
+	}
+	require.NoError(a.setup())
+	docs, err := a.build()
+	require.NoError(err)
+
+	// Both are documented, under distinct anchors (and links follow them),
+	// numbered after the property headings rendered before them.
+	require.Len(docs, 4)
+	anchors := map[string]string{}
+	for _, doc := range docs {
+		anchors[doc.Entity.EntityURI()] = doc.Entity.EntityAnchor()
+	}
+	require.Equal(map[string]string{
+		"https://example.com/one.schema.json":                     "one",
+		"https://example.com/one.schema.json#/definitions/Action": "action-1",
+		"https://example.com/two.schema.json":                     "two",
+		"https://example.com/two.schema.json#/definitions/Action": "action-3",
+	}, anchors)
+	require.Equal("#action-1", docs[0].Entity.Properties["action"].EntityLink())
+	require.Equal("#action-3", docs[2].Entity.Properties["action"].EntityLink())
+}
+
+func TestGenAction_run_WhenSingleAnchorsCollideWithProperties(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	// A property named after the entity it references.
+	schema := `{
+		"title": "Person",
+		"type": "object",
+		"properties": {"address": {"$ref": "#/definitions/Address"}},
+		"definitions": {"Address": {"type": "object"}}
+	}`
+	require.NoError(os.WriteFile(filepath.Join(dir, "person.schema.json"), []byte(schema), 0600))
+
+	a := &GenAction{
+		App:      core.NewTestApp(),
+		Format:   "html",
+		InPaths:  []string{filepath.Join(dir, "person.schema.json")},
+		Includes: []string{DefaultInclude},
+		OutDir:   filepath.Join(dir, "out"),
+		Single:   true,
+	}
+	require.NoError(a.run(t.Context()))
+	content, err := os.ReadFile(filepath.Join(dir, "out", "person.html"))
+	require.NoError(err)
+	out := string(content)
+
+	// The property heading comes first (so keeps the plain anchor),
+	// and the entity heading (and every link to it) is renamed after it.
+	require.Contains(out, `<h3 id="address"><code>address</code></h3>`)
+	require.Contains(out, `<h1 id="address-1">Address</h1>`)
+	require.Contains(out, `<a href="#address-1">Address</a>`)
+	require.NotContains(out, `<a href="#address">Address</a>`)
+}
+
+func TestGenAction_run_WhenCheck(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	schema := `{
+		"title": "Root",
+		"type": "object",
+		"properties": {"child": {"$ref": "#/definitions/Child"}},
+		"definitions": {"Child": {"type": "object"}}
+	}`
+	require.NoError(os.WriteFile(filepath.Join(dir, "root.schema.json"), []byte(schema), 0600))
+	outDir := filepath.Join(dir, "out")
+
+	run := func(check bool) (string, error) {
+		t.Helper()
+		a := &GenAction{
+			App:      core.NewTestApp(),
+			Format:   "markdown",
+			InPaths:  []string{filepath.Join(dir, "root.schema.json")},
+			Includes: []string{DefaultInclude},
+			OutDir:   outDir,
+			Check:    check,
+		}
+		err := a.run(t.Context())
+		return a.IO.Out.(fmt.Stringer).String(), err
+	}
+	// readOut returns the content of each file in the output dir.
+	readOut := func() map[string]string {
+		t.Helper()
+		files := map[string]string{}
+		entries, err := os.ReadDir(outDir)
+		require.NoError(err)
+		for _, entry := range entries {
+			content, err := os.ReadFile(filepath.Join(outDir, entry.Name()))
+			require.NoError(err)
+			files[entry.Name()] = string(content)
+		}
+		return files
+	}
+
+	_, err := run(false)
+	require.NoError(err)
+
+	// Up to date.
+	out, err := run(true)
+	require.NoError(err)
+	require.Empty(out)
+
+	// Stale (and missing) files are reported as a diff,
+	// but not written.
+	rootPath := filepath.Join(outDir, "root.md")
+	childPath := filepath.Join(outDir, "child.md")
+	files := readOut()
+	require.NoError(os.WriteFile(rootPath, []byte(strings.Replace(files["root.md"], "# Root", "# Stale", 1)), 0600))
+	require.NoError(os.Remove(childPath))
+	stale := readOut()
+
+	out, err = run(true)
+	require.EqualError(err, "2 generated file(s) are out of date")
+	require.Contains(out, "--- "+rootPath+"\n+++ "+rootPath+"\n@@ -1,4 +1,4 @@\n-# Stale\n+# Root\n")
+	require.Contains(out, "--- "+os.DevNull+"\n+++ "+childPath+"\n@@ -0,0 +1,6 @@\n+# Child\n")
+	require.Equal(stale, readOut())
+
+	// Until regenerated.
+	_, err = run(false)
+	require.NoError(err)
+	require.Equal(files, readOut())
+}
+
+func TestGenAction_run_WhenStdout(t *testing.T) {
+	dir := t.TempDir()
+	schema := `{
+		"title": "Root",
+		"type": "object",
+		"properties": {"child": {"$ref": "#/definitions/Child"}},
+		"definitions": {"Child": {"type": "object"}}
+	}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "root.schema.json"), []byte(schema), 0600))
+
+	run := func(t *testing.T, formatName string) (string, error) {
+		t.Helper()
+		a := &GenAction{
+			App:      core.NewTestApp(),
+			Format:   formatName,
+			InPaths:  []string{filepath.Join(dir, "root.schema.json")},
+			OutDir:   StdioPath,
+			Includes: []string{DefaultInclude},
+		}
+		err := a.run(t.Context())
+		return a.IO.Out.(fmt.Stringer).String(), err
+	}
+
+	t.Run("should separate documents with comments", func(t *testing.T) {
+		out, err := run(t, "restructuredtext")
+		require.NoError(t, err)
+		require.Contains(t, out, ".. schemadoc: root.rst\n\n")
+		require.Contains(t, out, ".. schemadoc: child.rst\n\n")
+	})
+
+	t.Run("should reject several documents without comments", func(t *testing.T) {
+		out, err := run(t, "json")
+		require.ErrorContains(t, err, "can not write 2 documents to stdout")
+		require.Empty(t, out)
+	})
+}
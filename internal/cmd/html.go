@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/twelvelabs/schemadoc/internal/format"
+	"github.com/twelvelabs/schemadoc/internal/jsonschema"
+	"github.com/twelvelabs/schemadoc/internal/markdown"
+)
+
+const (
+	// HTMLLayoutPath is the path of the page layout in the embedded templates.
+	HTMLLayoutPath = "templates/html/layout.tpl.html"
+	// HTMLStylePath is the path of the stylesheet in the embedded templates.
+	HTMLStylePath = "templates/html/style.css"
+	// HTMLStyleName is the name of the stylesheet in the output dir.
+	HTMLStyleName = "schemadoc.css"
+)
+
+// htmlPage is the data passed to the HTML layout.
+type htmlPage struct {
+	Content    template.HTML
+	Error      string
+	EventsPath string
+	Nav        []navItem
+	StylePath  string
+	Title      string
+}
+
+// navItem is a link in the page navigation.
+type navItem struct {
+	Active bool
+	Link   string
+	Name   string
+}
+
+// htmlLayout renders HTML pages.
+type htmlLayout struct {
+	tpl *template.Template
+}
+
+// newHTMLLayout parses the HTML layout: the one in the `html` subdir
+// of dir (a `--template` dir, as written by `schemadoc templates export`),
+// if any, or the embedded one.
+func newHTMLLayout(dir string) (*htmlLayout, error) {
+	content, err := readHTMLFile(dir, HTMLLayoutPath)
+	if err != nil {
+		return nil, err
+	}
+	tpl, err := template.New(path.Base(HTMLLayoutPath)).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("html layout: %w", err)
+	}
+	return &htmlLayout{tpl: tpl}, nil
+}
+
+// Render renders page.
+func (l *htmlLayout) Render(page *htmlPage) (string, error) {
+	buf := bytes.Buffer{}
+	if err := l.tpl.Execute(&buf, page); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// htmlStyle returns the stylesheet: the one in the `html` subdir
+// of dir (a `--template` dir), if any, or the embedded one.
+func htmlStyle(dir string) ([]byte, error) {
+	return readHTMLFile(dir, HTMLStylePath)
+}
+
+// readHTMLFile returns the content of the file at name in the embedded
+// templates, or of the file at the same path in dir (if any).
+func readHTMLFile(dir string, name string) ([]byte, error) {
+	if dir != "" {
+		rel := strings.TrimPrefix(name, TemplatesDir+"/")
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return content, err
+		}
+	}
+	return jsonschema.Templates.ReadFile(name)
+}
+
+// newHTMLPage returns the page for doc (or an empty page if doc is nil),
+// converting the rendered Markdown to HTML. The navigation links the index
+// (if any, which is the page when doc is nil) and every doc. All links
+// are relative to the page, so the site can be hosted anywhere.
+func newHTMLPage(doc *Doc, docs []*Doc, index *format.Page, rendered string) (*htmlPage, error) {
+	content, err := markdown.ToHTMLDocument([]byte(rendered))
+	if err != nil {
+		return nil, err
+	}
+
+	page := &htmlPage{
+		Content:   template.HTML(content), //nolint: gosec
+		StylePath: HTMLStyleName,
+		Title:     "schemadoc",
+	}
+	from := ""
+	if doc != nil {
+		from = doc.Path
+		page.StylePath = jsonschema.RelativePath(from, HTMLStyleName)
+		page.Title = doc.Entity.EntityName()
+	}
+	if index != nil {
+		page.Nav = append(page.Nav, navItem{
+			Active: doc == nil,
+			Link:   jsonschema.RelativePath(from, index.Path),
+			Name:   index.Title,
+		})
+	}
+	for _, other := range docs {
+		page.Nav = append(page.Nav, navItem{
+			Active: other == doc,
+			Link:   jsonschema.RelativePath(from, other.Path),
+			Name:   other.Entity.EntityName(),
+		})
+	}
+	return page, nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sebdah/goldie/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/twelvelabs/schemadoc/internal/core"
+	"github.com/twelvelabs/schemadoc/internal/format"
+	"github.com/twelvelabs/schemadoc/internal/jsonschema"
+)
+
+func TestNewHTMLPage(t *testing.T) {
+	require := require.New(t)
+	widget := &Doc{Entity: &jsonschema.Schema{Title: "Widget"}, Path: "v1/widget.html"}
+	gadget := &Doc{Entity: &jsonschema.Schema{Title: "Gadget"}, Path: "gadget.html"}
+	docs := []*Doc{widget, gadget}
+	index := &format.Page{Path: "index.html", Title: "Schemas"}
+
+	// Links are relative to the (nested) page.
+	page, err := newHTMLPage(widget, docs, index, "# Widget\n")
+	require.NoError(err)
+	require.Equal("Widget", page.Title)
+	require.Equal("../schemadoc.css", page.StylePath)
+	require.Equal([]navItem{
+		{Link: "../index.html", Name: "Schemas"},
+		{Link: "widget.html", Name: "Widget", Active: true},
+		{Link: "../gadget.html", Name: "Gadget"},
+	}, page.Nav)
+	require.Contains(string(page.Content), `<h1 id="widget">Widget</h1>`)
+
+	// Pages that are not the doc of an entity (i.e. the index) are at the root.
+	page, err = newHTMLPage(nil, docs, index, "")
+	require.NoError(err)
+	require.Equal("schemadoc.css", page.StylePath)
+	require.Equal([]navItem{
+		{Link: "index.html", Name: "Schemas", Active: true},
+		{Link: "v1/widget.html", Name: "Widget"},
+		{Link: "gadget.html", Name: "Gadget"},
+	}, page.Nav)
+}
+
+func TestGenAction_run_WhenHTML(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	schema := `{
+		"title": "Widget",
+		"description": "A widget.",
+		"type": "object",
+		"properties": {"name": {"type": "string", "description": "The name."}}
+	}`
+	require.NoError(os.MkdirAll(filepath.Join(dir, "schemas", "v1"), 0755))
+	require.NoError(os.WriteFile(filepath.Join(dir, "schemas", "v1", "widget.schema.json"), []byte(schema), 0600))
+
+	a := &GenAction{
+		App:      core.NewTestApp(),
+		Format:   "html",
+		InPaths:  []string{filepath.Join(dir, "schemas")},
+		Includes: []string{DefaultInclude},
+		OutDir:   filepath.Join(dir, "site"),
+		Index:    true,
+	}
+	require.NoError(a.run(t.Context()))
+	require.FileExists(filepath.Join(dir, "site", HTMLStyleName))
+	require.FileExists(filepath.Join(dir, "site", "index.html"))
+
+	// The nested page links the stylesheet, the index, and itself relative to its dir.
+	page, err := os.ReadFile(filepath.Join(dir, "site", "v1", "widget.html"))
+	require.NoError(err)
+	g := goldie.New(t)
+	g.Assert(t, "html_page", page)
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+const (
+	// ManifestName is the name of the manifest file in the output dir.
+	ManifestName = ".schemadoc-manifest.json"
+	// ManifestVersion is the version of the manifest file format.
+	ManifestVersion = 1
+)
+
+// ErrInvalidManifest is returned when the manifest can not be parsed.
+var ErrInvalidManifest = errors.New("invalid manifest")
+
+// Manifest lists the files generated to an output dir,
+// so that files no longer generated can be pruned.
+type Manifest struct {
+	Version int `json:"version"`
+	// Files are slash-separated paths relative to the output dir.
+	Files []string `json:"files"`
+}
+
+// ReadManifest reads the manifest in dir.
+// Returns an empty manifest if there is none.
+func ReadManifest(dir string) (*Manifest, error) {
+	manifest := &Manifest{Version: ManifestVersion}
+	buf, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(buf, manifest); err != nil {
+		return nil, fmt.Errorf("%w %s: %w", ErrInvalidManifest, filepath.Join(dir, ManifestName), err)
+	}
+	// Never track files outside of the output dir.
+	manifest.Files = slices.DeleteFunc(manifest.Files, func(file string) bool {
+		return !filepath.IsLocal(filepath.FromSlash(file))
+	})
+	return manifest, nil
+}
+
+// Write writes the manifest to dir.
+func (m *Manifest) Write(dir string) error {
+	slices.Sort(m.Files)
+	m.Files = slices.Compact(m.Files)
+	buf, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	buf = append(buf, '\n')
+	return os.WriteFile(filepath.Join(dir, ManifestName), buf, 0644) //nolint: gosec
+}
+
+// Stale returns the files in the manifest that are not in files.
+func (m *Manifest) Stale(files []string) []string {
+	stale := []string{}
+	for _, file := range m.Files {
+		if !slices.Contains(files, file) {
+			stale = append(stale, file)
+		}
+	}
+	return stale
+}
+
+// removeFile removes the file at name (relative to dir),
+// along with any parent dirs (within dir) left empty.
+func removeFile(dir string, name string) error {
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for parent := filepath.Dir(path); parent != filepath.Clean(dir); parent = filepath.Dir(parent) {
+		// Fails (as intended) if the dir is not empty.
+		if err := os.Remove(parent); err != nil {
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/twelvelabs/schemadoc/internal/core"
+)
+
+// writeManifest writes a manifest listing files to dir.
+func writeManifest(t *testing.T, dir string, files ...string) {
+	t.Helper()
+	require.NoError(t, (&Manifest{Version: ManifestVersion, Files: files}).Write(dir))
+}
+
+func TestReadManifest(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+
+	// Missing manifests are empty.
+	manifest, err := ReadManifest(dir)
+	require.NoError(err)
+	require.Equal(&Manifest{Version: ManifestVersion}, manifest)
+
+	// Entries outside of the dir are ignored.
+	writeManifest(t, dir, "a.md", "sub/b.md", "../outside.md", "/etc/passwd", "sub/../../outside.md")
+	manifest, err = ReadManifest(dir)
+	require.NoError(err)
+	require.Equal([]string{"a.md", "sub/b.md"}, manifest.Files)
+
+	// Corrupt manifests are an error.
+	require.NoError(os.WriteFile(filepath.Join(dir, ManifestName), []byte("{"), 0600))
+	_, err = ReadManifest(dir)
+	require.ErrorIs(err, ErrInvalidManifest)
+}
+
+func TestManifest_Stale(t *testing.T) {
+	manifest := &Manifest{Files: []string{"a.md", "b.md", "sub/c.md"}}
+	require.Equal(t, []string{"b.md", "sub/c.md"}, manifest.Stale([]string{"a.md", "d.md"}))
+	require.Equal(t, []string{}, manifest.Stale([]string{"a.md", "b.md", "sub/c.md"}))
+}
+
+func TestRemoveFile(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	writeFiles(t, dir, "a/b/c.md", "a/d.md")
+
+	// Removes dirs left empty (but not the dir itself, or non-empty dirs).
+	require.NoError(removeFile(dir, "a/b/c.md"))
+	require.NoDirExists(filepath.Join(dir, "a", "b"))
+	require.FileExists(filepath.Join(dir, "a", "d.md"))
+
+	require.NoError(removeFile(dir, "a/d.md"))
+	require.NoDirExists(filepath.Join(dir, "a"))
+	require.DirExists(dir)
+
+	// Missing files are ignored.
+	require.NoError(removeFile(dir, "unknown.md"))
+}
+
+func TestGenAction_updateManifest(t *testing.T) {
+	// Returns an output dir containing generated and stale files,
+	// alongside a file outside of it.
+	setup := func(t *testing.T) (string, string) {
+		t.Helper()
+		root := t.TempDir()
+		dir := filepath.Join(root, "out")
+		writeFiles(t, root, "outside.md", "out/user.md", "out/new.md", "out/old.md", "out/sub/old.md")
+		writeManifest(t, dir, "new.md", "old.md", "sub/old.md", "missing.md", "../outside.md")
+		return root, dir
+	}
+	newAction := func(dir string, check bool, prune bool) *GenAction {
+		return &GenAction{
+			App:    core.NewTestApp(),
+			OutDir: dir,
+			Check:  check,
+			Prune:  prune,
+		}
+	}
+
+	t.Run("should remove stale files when pruning", func(t *testing.T) {
+		require := require.New(t)
+		root, dir := setup(t)
+
+		stale, err := newAction(dir, false, true).updateManifest([]string{"new.md"})
+		require.NoError(err)
+		require.Equal(0, stale)
+		require.NoFileExists(filepath.Join(dir, "old.md"))
+		require.NoDirExists(filepath.Join(dir, "sub"))
+
+		// Untracked files (and those outside the dir) are never removed.
+		require.FileExists(filepath.Join(dir, "user.md"))
+		require.FileExists(filepath.Join(dir, "new.md"))
+		require.FileExists(filepath.Join(root, "outside.md"))
+
+		manifest, err := ReadManifest(dir)
+		require.NoError(err)
+		require.Equal([]string{"new.md"}, manifest.Files)
+	})
+
+	t.Run("should keep tracking stale files when not pruning", func(t *testing.T) {
+		require := require.New(t)
+		_, dir := setup(t)
+
+		_, err := newAction(dir, false, false).updateManifest([]string{"new.md"})
+		require.NoError(err)
+		require.FileExists(filepath.Join(dir, "old.md"))
+
+		// Files that no longer exist are dropped.
+		manifest, err := ReadManifest(dir)
+		require.NoError(err)
+		require.Equal([]string{"new.md", "old.md", "sub/old.md"}, manifest.Files)
+	})
+
+	t.Run("should count stale files without removing them when checking", func(t *testing.T) {
+		require := require.New(t)
+		_, dir := setup(t)
+		before, err := os.ReadFile(filepath.Join(dir, ManifestName))
+		require.NoError(err)
+
+		a := newAction(dir, true, true)
+		stale, err := a.updateManifest([]string{"new.md"})
+		require.NoError(err)
+		require.Equal(3, stale)
+		require.FileExists(filepath.Join(dir, "old.md"))
+		require.FileExists(filepath.Join(dir, "sub", "old.md"))
+		require.Contains(a.IO.Out.(fmt.Stringer).String(), "would remove "+filepath.Join(dir, "old.md"))
+
+		// The manifest is left as is.
+		after, err := os.ReadFile(filepath.Join(dir, ManifestName))
+		require.NoError(err)
+		require.Equal(string(before), string(after))
+
+		// Without pruning, stale files are not reported.
+		stale, err = newAction(dir, true, false).updateManifest([]string{"new.md"})
+		require.NoError(err)
+		require.Equal(0, stale)
+	})
+
+	t.Run("should ignore a corrupt manifest", func(t *testing.T) {
+		require := require.New(t)
+		_, dir := setup(t)
+		require.NoError(os.WriteFile(filepath.Join(dir, ManifestName), []byte("not json"), 0600))
+
+		_, err := newAction(dir, false, true).updateManifest([]string{"new.md"})
+		require.NoError(err)
+		require.FileExists(filepath.Join(dir, "old.md"))
+
+		manifest, err := ReadManifest(dir)
+		require.NoError(err)
+		require.Equal([]string{"new.md"}, manifest.Files)
+	})
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/gobuffalo/flect"
+	stripmd "github.com/writeas/go-strip-markdown"
+
+	"github.com/twelvelabs/schemadoc/internal/format"
+	"github.com/twelvelabs/schemadoc/internal/markdown"
+)
+
+// presetPages returns the preset pages for docs (in the same order).
+func presetPages(docs []*Doc) []format.Page {
+	pages := []format.Page{}
+	for idx, doc := range docs {
+		description := stripmd.Strip(doc.Entity.DescriptionMarkdown())
+		pages = append(pages, format.Page{
+			Path:        filepath.ToSlash(doc.Path),
+			Title:       doc.Entity.EntityName(),
+			Description: markdown.FirstSentence(description),
+			Position:    idx + 1,
+		})
+	}
+	return pages
+}
+
+// siteTitle returns the title of the index page (and root nav section):
+// the (titleized) name of the output dir.
+func siteTitle(outDir string) string {
+	if abs, err := filepath.Abs(outDir); err == nil {
+		outDir = abs
+	}
+	return flect.Titleize(filepath.Base(outDir))
+}
+
+// navBasePresets returns the names of the presets using [GenAction.PresetBase].
+func navBasePresets() string {
+	names := []string{}
+	for _, name := range format.PresetNames() {
+		if preset, err := format.LookupPreset(name); err == nil && preset.NavBase {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, " and ")
+}
@@ -46,6 +46,8 @@ func NewRootCmd(app *core.App) *cobra.Command {
 
 	cmd.AddCommand(NewGenCmd(app))
 	cmd.AddCommand(NewManCmd(app))
+	cmd.AddCommand(NewServeCmd(app))
+	cmd.AddCommand(NewTemplatesCmd(app))
 	cmd.AddCommand(NewVersionCmd(app))
 
 	return cmd
@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/creasty/defaults"
+	"github.com/spf13/cobra"
+
+	"github.com/twelvelabs/schemadoc/internal/core"
+)
+
+// ServeEventsPath is the path of the server-sent events endpoint
+// used to live-reload pages.
+const ServeEventsPath = "/_schemadoc/events"
+
+func NewServeCmd(app *core.App) *cobra.Command {
+	a := &ServeAction{
+		GenAction: &GenAction{
+			App: app,
+		},
+	}
+	if err := defaults.Set(a); err != nil {
+		panic(err)
+	}
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve a live-reloading HTML preview of the documents",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.Run(cmd.Context(), args)
+		},
+	}
+
+	flags := cmd.Flags()
+	a.addInputFlags(flags)
+	flags.StringVarP(&a.Addr, "addr", "a", a.Addr, "address to listen on")
+
+	return cmd
+}
+
+type ServeAction struct {
+	*GenAction
+
+	Addr string `validate:"required" default:"localhost:8080"`
+
+	docs     []*Doc
+	err      error
+	layout   *htmlLayout
+	mu       sync.RWMutex
+	pages    map[string]*htmlPage
+	paths    []string
+	reloadMu sync.Mutex
+	reloads  map[chan struct{}]bool
+	style    []byte
+}
+
+func (a *ServeAction) Run(ctx context.Context, _ []string) error {
+	a.InMemory = true
+	if err := registerConfigFuncs(a.App); err != nil {
+		return fmt.Errorf("funcs config: %w", err)
+	}
+	if err := a.setup(); err != nil {
+		return err
+	}
+	// Reloaded by every rebuild (so that changes to
+	// a customized layout or stylesheet are served).
+	layout, err := newHTMLLayout(a.htmlTemplateDir())
+	if err != nil {
+		return err
+	}
+	style, err := htmlStyle(a.htmlTemplateDir())
+	if err != nil {
+		return err
+	}
+	a.layout = layout
+	a.style = style
+	a.reloads = map[chan struct{}]bool{}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	// Stops the server (and the watcher) when either fails.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	listener, err := (&net.ListenConfig{}).Listen(ctx, "tcp", a.Addr)
+	if err != nil {
+		return fmt.Errorf(`'--addr': %w`, err)
+	}
+	server := &http.Server{
+		Handler:           a,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	watchErr := make(chan error, 1)
+	go func() {
+		defer cancel()
+		watchErr <- a.watch(ctx, a.rebuild)
+	}()
+
+	fmt.Fprintf(a.IO.Err, "Serving docs at http://%s/ (press ctrl-c to exit)\n", listener.Addr())
+	err = server.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+	cancel()
+	return errors.Join(err, <-watchErr)
+}
+
+// rebuild renders all docs in-memory and tells the browser to reload.
+// Docs are always rebuilt in full, since links between pages
+// (and the navigation) may change.
+func (a *ServeAction) rebuild(_ map[string]bool) ([]string, error) {
+	deps, err := a.render()
+
+	a.mu.Lock()
+	a.err = err
+	a.mu.Unlock()
+
+	a.reload()
+	return deps, err
+}
+
+// render renders all docs to HTML pages, replacing the served pages
+// (and the layout and stylesheet) on success.
+func (a *ServeAction) render() ([]string, error) {
+	docs, err := a.build()
+	if err != nil {
+		return nil, err
+	}
+	layout, err := newHTMLLayout(a.htmlTemplateDir())
+	if err != nil {
+		return nil, err
+	}
+	style, err := htmlStyle(a.htmlTemplateDir())
+	if err != nil {
+		return nil, err
+	}
+
+	pages := map[string]*htmlPage{}
+	for _, doc := range docs {
+		rendered, err := a.renderSchema(doc.Entity)
+		if err != nil {
+			return nil, err
+		}
+		page, err := newHTMLPage(doc, docs, nil, rendered)
+		if err != nil {
+			return nil, err
+		}
+		page.EventsPath = ServeEventsPath
+		pages[doc.Path] = page
+	}
+
+	a.mu.Lock()
+	a.docs = docs
+	a.layout = layout
+	a.style = style
+	a.pages = pages
+	a.paths = docPaths(docs)
+	a.mu.Unlock()
+
+	return docDeps(docs), nil
+}
+
+// ServeHTTP serves the rendered pages (at their generated paths),
+// the stylesheet, and the live-reload events.
+func (a *ServeAction) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	if r.URL.Path == ServeEventsPath {
+		a.serveEvents(w, r)
+		return
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if r.URL.Path == "/"+HTMLStyleName {
+		w.Header().Set("Content-Type", "text/css; charset=utf-8")
+		_, _ = w.Write(a.style)
+		return
+	}
+
+	if name == "" && len(a.paths) > 0 {
+		http.Redirect(w, r, "/"+a.paths[0], http.StatusFound)
+		return
+	}
+	page, ok := a.pages[name]
+	switch {
+	case ok:
+		// Copy, so the (shared) page is not modified.
+		copied := *page
+		page = &copied
+	case a.err != nil:
+		// Show the error (and the navigation to the last successfully
+		// built docs, if any) on an otherwise empty page.
+		var err error
+		if page, err = newHTMLPage(nil, a.docs, nil, ""); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		page.EventsPath = ServeEventsPath
+		page.StylePath = "/" + HTMLStyleName
+		// The page may be served at any path, so links must be absolute.
+		for i := range page.Nav {
+			page.Nav[i].Link = "/" + page.Nav[i].Link
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if a.err != nil {
+		page.Error = a.err.Error()
+	}
+
+	rendered, err := a.layout.Render(page)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(rendered))
+}
+
+// serveEvents sends a `reload` event every time the docs are rebuilt.
+func (a *ServeAction) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher.Flush()
+
+	reload := make(chan struct{}, 1)
+	a.reloadMu.Lock()
+	a.reloads[reload] = true
+	a.reloadMu.Unlock()
+	defer func() {
+		a.reloadMu.Lock()
+		delete(a.reloads, reload)
+		a.reloadMu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-reload:
+			fmt.Fprint(w, "event: reload\ndata: {}\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// reload notifies all connected browsers to reload.
+func (a *ServeAction) reload() {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+	for reload := range a.reloads {
+		select {
+		case reload <- struct{}{}:
+		default:
+			// Already pending.
+		}
+	}
+}
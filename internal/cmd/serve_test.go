@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/creasty/defaults"
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/require"
+
+	"github.com/twelvelabs/schemadoc/internal/core"
+	"github.com/twelvelabs/schemadoc/internal/jsonschema"
+)
+
+func TestServeAction_ServeHTTP_WhenError(t *testing.T) {
+	require := require.New(t)
+	layout, err := newHTMLLayout("")
+	require.NoError(err)
+
+	a := &ServeAction{
+		docs: []*Doc{
+			{Entity: &jsonschema.Schema{Title: "Widget"}, Path: "v1/widget.html"},
+		},
+		err:    errors.New("boom"),
+		layout: layout,
+		pages:  map[string]*htmlPage{},
+	}
+
+	// Pages that failed to build show the error,
+	// along with the navigation to the last built docs.
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/gadget.html", nil))
+	require.Equal(http.StatusOK, w.Code)
+	require.Contains(w.Body.String(), `<pre class="error">boom</pre>`)
+	require.Contains(w.Body.String(), `<a href="/v1/widget.html">Widget</a>`)
+
+	// Without an error, unknown pages are not found.
+	a.err = nil
+	w = httptest.NewRecorder()
+	a.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/gadget.html", nil))
+	require.Equal(http.StatusNotFound, w.Code)
+}
+
+func TestServeAction_rebuild_WhenFirstBuildFails(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "root.schema.json")
+	broken := `{"title": "Root", "properties": {"a": {"$ref": "missing.schema.json"}}}`
+	require.NoError(os.WriteFile(path, []byte(broken), 0600))
+
+	a := &ServeAction{
+		GenAction: &GenAction{
+			App:      core.NewTestApp(),
+			InPaths:  []string{path},
+			Includes: []string{DefaultInclude},
+			InMemory: true,
+		},
+		reloads: map[chan struct{}]bool{},
+	}
+	require.NoError(defaults.Set(a))
+	require.NoError(a.setup())
+	deps, err := a.rebuild(nil)
+	require.Error(err)
+	require.Empty(a.pages)
+
+	watcher, err := fsnotify.NewWatcher()
+	require.NoError(err)
+	defer watcher.Close()
+	a.watchFiles(watcher, deps)
+
+	// Fixing the schema replaces the error with the pages.
+	require.NoError(os.WriteFile(path, []byte(`{"title": "Root", "type": "object"}`), 0600))
+	deps = a.regenerate(watcher, a.rebuild, deps, map[string]bool{path: true})
+	require.Equal([]string{path}, deps)
+	require.NoError(a.err)
+	require.Contains(a.pages, "root.md")
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/twelvelabs/schemadoc/internal/core"
+)
+
+// runTargets runs the targets in the config (or only the one named by `--target`).
+func (a *GenAction) runTargets(ctx context.Context) error {
+	targets, err := a.selectTargets()
+	if err != nil {
+		return err
+	}
+	if a.Watch && len(targets) > 1 {
+		return fmt.Errorf(`'--watch': can only watch a single target (use '--target')`)
+	}
+
+	// Validate every target (with the flags applied, as `gen` validates flags)
+	// before generating any.
+	for _, target := range targets {
+		if err := a.forTarget(target).setup(); err != nil {
+			return fmt.Errorf("target %q: %w", target.Name, err)
+		}
+	}
+
+	// Run every target (even if one fails), so that `--check` reports them all.
+	errs := []error{}
+	for _, target := range targets {
+		a.Logger.Info("Generating target", "name", target.Name)
+		if err := a.forTarget(target).run(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("target %q: %w", target.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// selectTargets returns the targets to run.
+func (a *GenAction) selectTargets() ([]core.TargetConfig, error) {
+	// Already validated when loading the config file,
+	// but the config may also have been built in code.
+	if err := a.Config.ValidateTargets(); err != nil {
+		return nil, fmt.Errorf("targets config: %w", err)
+	}
+	if a.Target == "" {
+		return a.Config.Targets, nil
+	}
+	names := []string{}
+	for _, target := range a.Config.Targets {
+		names = append(names, target.Name)
+	}
+	idx := slices.Index(names, a.Target)
+	if idx < 0 {
+		return nil, fmt.Errorf(`'--target': unknown target %q (must be one of %v)`, a.Target, names)
+	}
+	return a.Config.Targets[idx : idx+1], nil
+}
+
+// forTarget returns a copy of the action configured for target.
+// Flags set on the command line take precedence over the target.
+// Target paths are relative to the config file (and flags to the working dir).
+func (a *GenAction) forTarget(target core.TargetConfig) *GenAction {
+	t := *a
+	setString := func(flag string, dst *string, value string) {
+		if value != "" && !a.changed(flag) {
+			*dst = value
+		}
+	}
+	setString("out", &t.OutDir, a.resolveTargetPath(target.Out))
+	setString("format", &t.Format, target.Format)
+	setString("preset", &t.Preset, target.Preset)
+	setString("preset-base", &t.PresetBase, target.PresetBase)
+	setString("template", &t.TemplatePath, a.resolveTargetPath(target.Template))
+	setString("outfile", &t.OutFile, target.OutFile)
+	setString("index-template", &t.IndexTemplatePath, a.resolveTargetPath(target.IndexTemplate))
+	if len(target.In) > 0 && !a.changed("in") {
+		t.InPaths = []string{}
+		for _, in := range target.In {
+			// Not on disk, so likely the name of a catalog entry.
+			if resolved := a.resolveTargetPath(in); fileExists(resolved) {
+				in = resolved
+			}
+			t.InPaths = append(t.InPaths, in)
+		}
+	}
+	if target.Index && !a.changed("index") {
+		t.Index = true
+	}
+	if target.Single && !a.changed("single") {
+		t.Single = true
+	}
+	t.Includes = slices.Concat(target.Include, a.Includes)
+	t.Excludes = slices.Concat(target.Exclude, a.Excludes)
+	return &t
+}
+
+// resolveTargetPath returns path (from a target) relative to the config file.
+func (a *GenAction) resolveTargetPath(path string) string {
+	if path == StdioPath {
+		return path
+	}
+	return a.Config.ResolvePath(path)
+}
+
+// fileExists returns true if there is a file (or dir) at path.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// changed returns true if the flag named name was set on the command line.
+func (a *GenAction) changed(name string) bool {
+	return a.flags != nil && a.flags.Changed(name)
+}
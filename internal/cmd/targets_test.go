@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+
+	"github.com/twelvelabs/schemadoc/internal/core"
+)
+
+// newTargetsAction returns an action for the given targets.
+func newTargetsAction(targets ...core.TargetConfig) *GenAction {
+	app := core.NewTestApp()
+	app.Config.Targets = targets
+	return &GenAction{
+		App:      app,
+		Format:   "markdown",
+		OutDir:   "docs",
+		Includes: []string{DefaultInclude},
+	}
+}
+
+func TestGenAction_selectTargets(t *testing.T) {
+	api := core.TargetConfig{Name: "api", Out: "docs/api"}
+	site := core.TargetConfig{Name: "site", Out: "site", Preset: "hugo"}
+
+	t.Run("should select every target by default", func(t *testing.T) {
+		targets, err := newTargetsAction(api, site).selectTargets()
+		require.NoError(t, err)
+		require.Equal(t, []core.TargetConfig{api, site}, targets)
+	})
+
+	t.Run("should select the named target", func(t *testing.T) {
+		a := newTargetsAction(api, site)
+		a.Target = "site"
+		targets, err := a.selectTargets()
+		require.NoError(t, err)
+		require.Equal(t, []core.TargetConfig{site}, targets)
+	})
+
+	t.Run("should reject unknown targets", func(t *testing.T) {
+		a := newTargetsAction(api, site)
+		a.Target = "docs"
+		_, err := a.selectTargets()
+		require.ErrorContains(t, err, `'--target': unknown target "docs" (must be one of [api site])`)
+	})
+
+	t.Run("should reject invalid targets", func(t *testing.T) {
+		_, err := newTargetsAction(api, api).selectTargets()
+		require.ErrorContains(t, err, `targets config: duplicate target "api"`)
+	})
+}
+
+func TestGenAction_runTargets_WhenInvalid(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "schemas/a.schema.json")
+	api := core.TargetConfig{Name: "api", In: []string{filepath.Join(dir, "schemas")}, Out: filepath.Join(dir, "api")}
+	site := core.TargetConfig{Name: "site", In: api.In, Out: filepath.Join(dir, "site"), Preset: "hugo", Index: true}
+
+	// Every target is validated (as the flags are) before any is generated.
+	err := newTargetsAction(api, site).runTargets(t.Context())
+	require.ErrorContains(t, err, `target "site": '--index': can not be used with the hugo preset`)
+	entries, _ := os.ReadDir(filepath.Join(dir, "api"))
+	require.Empty(t, entries)
+}
+
+func TestGenAction_forTarget(t *testing.T) {
+	require := require.New(t)
+	a := newTargetsAction()
+	a.Excludes = []string{"vendor/**"}
+
+	got := a.forTarget(core.TargetConfig{
+		Name:     "site",
+		In:       []string{"schemas"},
+		Out:      "site/content",
+		Format:   "asciidoc",
+		Preset:   "hugo",
+		Template: "templates",
+		OutFile:  "{{ .EntityName }}.adoc",
+		Single:   true,
+		Include:  []string{"*.json"},
+		Exclude:  []string{"**/internal/**"},
+	})
+	require.Equal([]string{"schemas"}, got.InPaths)
+	require.Equal("site/content", got.OutDir)
+	require.Equal("asciidoc", got.Format)
+	require.Equal("hugo", got.Preset)
+	require.Equal("templates", got.TemplatePath)
+	require.Equal("{{ .EntityName }}.adoc", got.OutFile)
+	require.True(got.Single)
+	// Patterns are added to the global ones.
+	require.Equal([]string{"*.json", DefaultInclude}, got.Includes)
+	require.Equal([]string{"**/internal/**", "vendor/**"}, got.Excludes)
+
+	// The action itself is unchanged.
+	require.Equal("docs", a.OutDir)
+	require.Empty(a.InPaths)
+	require.Equal([]string{"vendor/**"}, a.Excludes)
+
+	// Unset options keep the action's values.
+	got = a.forTarget(core.TargetConfig{Name: "api"})
+	require.Equal("docs", got.OutDir)
+	require.Equal("markdown", got.Format)
+}
+
+func TestGenAction_forTarget_WhenFlagsChanged(t *testing.T) {
+	require := require.New(t)
+	a := newTargetsAction()
+	flags := pflag.NewFlagSet("gen", pflag.ContinueOnError)
+	flags.StringSliceVar(&a.InPaths, "in", a.InPaths, "")
+	flags.StringVarP(&a.OutDir, "out", "o", a.OutDir, "")
+	flags.StringVar(&a.Format, "format", a.Format, "")
+	flags.BoolVar(&a.Single, "single", a.Single, "")
+	require.NoError(flags.Parse([]string{"--in", "other", "--out", "build", "--single=false"}))
+	a.flags = flags
+
+	// Flags set on the command line take precedence over the target
+	// (even when set to their default value).
+	got := a.forTarget(core.TargetConfig{
+		Name:   "site",
+		In:     []string{"schemas"},
+		Out:    "site/content",
+		Format: "asciidoc",
+		Single: true,
+	})
+	require.Equal([]string{"other"}, got.InPaths)
+	require.Equal("build", got.OutDir)
+	require.False(got.Single)
+	// Others are set by the target.
+	require.Equal("asciidoc", got.Format)
+}
+
+func TestGenAction_forTarget_ResolvesPaths(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	configDir := filepath.Join(dir, "cfg")
+	writeFiles(t, configDir, "schemas/a.schema.json")
+
+	a := newTargetsAction()
+	a.Config.ConfigPath = filepath.Join(configDir, ".schemadoc.yaml")
+	target := core.TargetConfig{
+		Name:          "site",
+		In:            []string{"schemas", "https://example.com/a.schema.json", "pets"},
+		Out:           "../site",
+		Template:      "templates",
+		IndexTemplate: "templates/index.tpl.md",
+	}
+
+	// Target paths are relative to the config file (not the working dir).
+	got := a.forTarget(target)
+	require.Equal([]string{
+		filepath.Join(configDir, "schemas"),
+		"https://example.com/a.schema.json",
+		// Not a file, so left for catalog lookups.
+		"pets",
+	}, got.InPaths)
+	require.Equal(filepath.Join(dir, "site"), got.OutDir)
+	require.Equal(filepath.Join(configDir, "templates"), got.TemplatePath)
+	require.Equal(filepath.Join(configDir, "templates", "index.tpl.md"), got.IndexTemplatePath)
+
+	// Except for stdio.
+	target.Out = StdioPath
+	require.Equal(StdioPath, a.forTarget(target).OutDir)
+
+	// Flags stay relative to the working dir.
+	flags := pflag.NewFlagSet("gen", pflag.ContinueOnError)
+	flags.StringVar(&a.TemplatePath, "template", a.TemplatePath, "")
+	require.NoError(flags.Parse([]string{"--template", "templates"}))
+	a.flags = flags
+	require.Equal("templates", a.forTarget(target).TemplatePath)
+}
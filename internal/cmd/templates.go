@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"github.com/twelvelabs/termite/render"
+
+	"github.com/twelvelabs/schemadoc/internal/core"
+	"github.com/twelvelabs/schemadoc/internal/format"
+	"github.com/twelvelabs/schemadoc/internal/jsonschema"
+)
+
+// TemplatesDir is the dir of the built-in templates in [jsonschema.Templates].
+const TemplatesDir = "templates"
+
+func NewTemplatesCmd(app *core.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "templates",
+		Short: "Inspect and export the built-in templates",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List the built-in templates (and the formats using them)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listTemplates(app)
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "show <name|format>",
+		Short: "Print a built-in template (or the entity template of a format)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, err := templatePath(args[0])
+			if err != nil {
+				return err
+			}
+			content, err := jsonschema.Templates.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			_, err = app.IO.Out.Write(content)
+			return err
+		},
+	})
+
+	export := &templatesExport{App: app, Dir: TemplatesDir}
+	exportCmd := &cobra.Command{
+		Use:   "export [dir]",
+		Short: "Copy the built-in templates to a dir (for customizing)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				export.Dir = args[0]
+			}
+			return export.Run()
+		},
+	}
+	exportCmd.Flags().StringVar(&export.Format, "format", export.Format, "only export the templates of a format")
+	exportCmd.Flags().BoolVar(&export.Force, "force", export.Force, "overwrite existing files")
+	cmd.AddCommand(exportCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "funcs",
+		Short: "List the functions available to templates",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := registerConfigFuncs(app); err != nil {
+				return fmt.Errorf("funcs config: %w", err)
+			}
+			for _, line := range format.DescribeFuncs(render.FuncMap) {
+				app.UI.Out("%s\n", line)
+			}
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "model",
+		Short: "Describe the data passed to templates",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app.UI.Out("Entity templates are executed with a *jsonschema.Schema.\n\n")
+			for _, v := range []any{&jsonschema.Schema{}, jsonschema.TypeInfo{}, &jsonschema.Any{}} {
+				app.UI.Out("%s\n", format.DescribeType(v))
+			}
+			app.UI.Out("Index templates are executed with a *format.Index.\n\n")
+			for _, v := range []any{&format.Index{}, &format.IndexGroup{}, &format.IndexEntry{}} {
+				app.UI.Out("%s\n", format.DescribeType(v))
+			}
+			return nil
+		},
+	})
+
+	return cmd
+}
+
+// listTemplates prints each built-in template, the formats using it,
+// and the named templates it defines (which a `--template` dir can override).
+func listTemplates(app *core.App) error {
+	names, err := templateNames()
+	if err != nil {
+		return err
+	}
+	users := templateFormats()
+
+	w := tabwriter.NewWriter(app.IO.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TEMPLATE\tFORMATS\tDEFINES")
+	for _, name := range names {
+		defines := []string{}
+		if strings.Contains(name, ".tpl.") {
+			tpl, err := template.New(path.Base(name)).Funcs(render.FuncMap).ParseFS(jsonschema.Templates, name)
+			if err != nil {
+				return err
+			}
+			for _, t := range tpl.Templates() {
+				if t.Name() != path.Base(name) {
+					defines = append(defines, t.Name())
+				}
+			}
+			slices.Sort(defines)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n",
+			strings.TrimPrefix(name, TemplatesDir+"/"),
+			strings.Join(users[name], ", "),
+			strings.Join(defines, ", "),
+		)
+	}
+	return w.Flush()
+}
+
+// templateNames returns the paths of the built-in templates.
+func templateNames() ([]string, error) {
+	names := []string{}
+	err := fs.WalkDir(jsonschema.Templates, TemplatesDir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			names = append(names, name)
+		}
+		return nil
+	})
+	return names, err
+}
+
+// templateFormats returns the names of the formats using each template.
+func templateFormats() map[string][]string {
+	users := map[string][]string{}
+	for _, name := range format.Names() {
+		f, err := format.Lookup(name)
+		if err != nil {
+			continue
+		}
+		paths := []string{f.Template, f.IndexTemplate}
+		if f.HTMLLayout {
+			paths = append(paths, HTMLLayoutPath, HTMLStylePath)
+		}
+		for _, p := range paths {
+			if p != "" && !slices.Contains(users[p], name) {
+				users[p] = append(users[p], name)
+			}
+		}
+	}
+	return users
+}
+
+// templatePath returns the path of the built-in template named name:
+// either a path relative to the templates dir, or a format name
+// (for the format's entity template).
+func templatePath(name string) (string, error) {
+	if f, err := format.Lookup(name); err == nil {
+		return f.Template, nil
+	}
+	names, err := templateNames()
+	if err != nil {
+		return "", err
+	}
+	full := path.Join(TemplatesDir, name)
+	if !slices.Contains(names, full) {
+		return "", fmt.Errorf("unknown template %q (see `schemadoc templates list`)", name)
+	}
+	return full, nil
+}
+
+// templatesExport copies the built-in templates to a dir.
+type templatesExport struct {
+	*core.App
+
+	Dir    string
+	Force  bool
+	Format string
+}
+
+func (e *templatesExport) Run() error {
+	names, err := templateNames()
+	if err != nil {
+		return err
+	}
+	if e.Format != "" {
+		if _, err := format.Lookup(e.Format); err != nil {
+			return fmt.Errorf(`'--format': %w`, err)
+		}
+		users := templateFormats()
+		names = slices.DeleteFunc(names, func(name string) bool {
+			return !slices.Contains(users[name], e.Format)
+		})
+	}
+
+	for _, name := range names {
+		dest := filepath.Join(e.Dir, filepath.FromSlash(strings.TrimPrefix(name, TemplatesDir+"/")))
+		if _, err := os.Stat(dest); err == nil && !e.Force {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", dest)
+		} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		content, err := jsonschema.Templates.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil { //nolint: gosec
+			return err
+		}
+		if err := os.WriteFile(dest, content, 0644); err != nil { //nolint: gosec
+			return err
+		}
+		e.Logger.Info("Exported", "path", dest)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/require"
+	"github.com/twelvelabs/termite/render"
+
+	"github.com/twelvelabs/schemadoc/internal/core"
+	"github.com/twelvelabs/schemadoc/internal/format"
+)
+
+func TestTemplatesExport_Override(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "templates")
+	require.NoError(t, (&templatesExport{App: core.NewTestApp(), Dir: dir}).Run())
+
+	// The exported templates override those of every format (with themselves).
+	for _, name := range format.Names() {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			f, err := format.Lookup(name)
+			require.NoError(err)
+
+			builtin, err := f.Parse(render.FuncMap)
+			require.NoError(err)
+			tpl, err := f.Parse(render.FuncMap)
+			require.NoError(err)
+			require.NoError(f.Override(tpl, dir))
+			require.ElementsMatch(templateNamesOf(builtin), templateNamesOf(tpl))
+
+			index, err := f.ParseIndex(render.FuncMap)
+			require.NoError(err)
+			require.NoError(f.OverrideIndex(index, dir))
+		})
+	}
+
+	// Changes to an exported template are rendered.
+	require := require.New(t)
+	path := filepath.Join(dir, "markdown.tpl.md")
+	content, err := os.ReadFile(path)
+	require.NoError(err)
+	require.NoError(os.WriteFile(path, append([]byte("Customized\n"), content...), 0600))
+
+	f, err := format.Lookup(format.Markdown)
+	require.NoError(err)
+	tpl, err := f.Parse(render.FuncMap)
+	require.NoError(err)
+	require.NoError(f.Override(tpl, dir))
+	buf := bytes.Buffer{}
+	require.NoError(tpl.Execute(&buf, nil))
+	require.Contains(buf.String(), "Customized\n")
+}
+
+func TestTemplatesExport_HTML(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	tplDir := filepath.Join(dir, "templates")
+	require.NoError((&templatesExport{App: core.NewTestApp(), Dir: tplDir, Format: "html"}).Run())
+	require.NoError(os.Mkdir(filepath.Join(dir, "schemas"), 0755))
+	schema := []byte(`{"title": "Widget", "type": "object"}`)
+	require.NoError(os.WriteFile(filepath.Join(dir, "schemas", "widget.schema.json"), schema, 0600))
+
+	// Changes to the exported layout and stylesheet are used.
+	layoutPath := filepath.Join(tplDir, "html", "layout.tpl.html")
+	content, err := os.ReadFile(layoutPath)
+	require.NoError(err)
+	content = bytes.Replace(content, []byte("<head>"), []byte(`<head><meta name="custom">`), 1)
+	require.NoError(os.WriteFile(layoutPath, content, 0600))
+	require.NoError(os.WriteFile(filepath.Join(tplDir, "html", "style.css"), []byte("body {}\n"), 0600))
+
+	a := &GenAction{
+		App:          core.NewTestApp(),
+		Format:       "html",
+		InPaths:      []string{filepath.Join(dir, "schemas")},
+		Includes:     []string{DefaultInclude},
+		OutDir:       filepath.Join(dir, "out"),
+		TemplatePath: tplDir,
+	}
+	require.NoError(a.run(t.Context()))
+	page, err := os.ReadFile(filepath.Join(dir, "out", "widget.html"))
+	require.NoError(err)
+	require.Contains(string(page), `<head><meta name="custom">`)
+	style, err := os.ReadFile(filepath.Join(dir, "out", HTMLStyleName))
+	require.NoError(err)
+	require.Equal("body {}\n", string(style))
+}
+
+// templateNamesOf returns the names of the templates associated with tpl.
+func templateNamesOf(tpl *template.Template) []string {
+	names := []string{}
+	for _, t := range tpl.Templates() {
+		names = append(names, t.Name())
+	}
+	return names
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/twelvelabs/schemadoc/internal/jsonschema"
+)
+
+// watchDebounce is how long to wait for further changes
+// before regenerating (editors often write files in several steps).
+const watchDebounce = 100 * time.Millisecond
+
+// generateFunc (re)generates the docs depending on the affected files
+// (or all docs when nil), returning the local files the docs depend on.
+type generateFunc func(affected map[string]bool) ([]string, error)
+
+// watch calls generate, then calls it again with the affected files
+// whenever the input schemas, their local $ref targets, or
+// the custom template change, until ctx is done.
+// Errors are reported without exiting.
+func (a *GenAction) watch(ctx context.Context, generate generateFunc) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	deps, err := generate(nil)
+	if err != nil {
+		a.Logger.Error("Generate failed", "err", err)
+	}
+	a.watchFiles(watcher, deps)
+	a.Logger.Info("Watching for changes (press ctrl-c to exit)")
+
+	changed := map[string]bool{}
+	timer := time.NewTimer(0)
+	<-timer.C
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			a.Logger.Error("Watch failed", "err", err)
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+				continue
+			}
+			changed[filepath.Clean(event.Name)] = true
+			timer.Reset(watchDebounce)
+		case <-timer.C:
+			deps = a.regenerate(watcher, generate, deps, changed)
+			clear(changed)
+		}
+	}
+}
+
+// regenerate regenerates the docs affected by the changed files
+// and returns the (updated) dependencies.
+func (a *GenAction) regenerate(
+	watcher *fsnotify.Watcher, generate generateFunc, deps []string, changed map[string]bool,
+) []string {
+	start := time.Now()
+
+	// Changes to the templates (or to the set of schemas in
+	// the input dirs) affect every doc, as do all changes
+	// until a build succeeds (since deps are unknown until then).
+	affected := changed
+	if deps == nil {
+		affected = nil
+	}
+	for _, path := range []string{a.TemplatePath, a.IndexTemplatePath} {
+		if path != "" && changed[filepath.Clean(path)] {
+			affected = nil
+		}
+	}
+	if a.templateDir {
+		for path := range changed {
+			dir := filepath.Dir(path)
+			if dir == filepath.Clean(a.TemplatePath) || dir == filepath.Join(a.TemplatePath, "html") {
+				affected = nil
+			}
+		}
+	}
+	paths := slices.Clone(a.SchemaPaths)
+	if err := a.discoverSchemas(); err != nil {
+		a.Logger.Error("Generate failed", "err", err)
+		return deps
+	}
+	if !slices.Equal(paths, a.SchemaPaths) {
+		affected = nil
+	}
+	if affected != nil && !slices.ContainsFunc(deps, func(dep string) bool { return affected[dep] }) {
+		// Some other file in a watched dir.
+		return deps
+	}
+
+	updated, err := generate(affected)
+	if err != nil {
+		a.Logger.Error("Generate failed", "err", err)
+		return deps
+	}
+	a.watchFiles(watcher, updated)
+	a.Logger.Info("Regenerated", "duration", time.Since(start))
+	return updated
+}
+
+// watchFiles adds the dirs containing deps, the custom templates,
+// the local input dirs (recursively), and the dirs containing
+// the local input files to watcher (so that changes are seen
+// even when no build has succeeded yet).
+// Dirs are watched (rather than files) so that changes are seen even
+// when editors replace files rather than write to them.
+func (a *GenAction) watchFiles(watcher *fsnotify.Watcher, deps []string) {
+	dirs := []string{}
+	for _, dep := range deps {
+		dirs = append(dirs, filepath.Dir(dep))
+	}
+	for _, tplPath := range []*string{&a.TemplatePath, &a.IndexTemplatePath} {
+		if *tplPath == "" {
+			continue
+		}
+		if path, err := filepath.Abs(*tplPath); err == nil {
+			*tplPath = path
+			if tplPath == &a.TemplatePath && a.templateDir {
+				// Including the HTML layout and stylesheet.
+				dirs = append(dirs, path, filepath.Join(path, "html"))
+			} else {
+				dirs = append(dirs, filepath.Dir(path))
+			}
+		}
+	}
+	for _, inPath := range a.InPaths {
+		info, err := os.Stat(inPath)
+		switch {
+		case err != nil:
+			continue
+		case info.IsDir():
+			_ = filepath.WalkDir(inPath, func(path string, entry fs.DirEntry, err error) error {
+				if err == nil && entry.IsDir() {
+					dirs = append(dirs, path)
+				}
+				return nil
+			})
+		default:
+			dirs = append(dirs, filepath.Dir(inPath))
+		}
+	}
+
+	watched := watcher.WatchList()
+	for _, dir := range dirs {
+		dir, err := filepath.Abs(dir)
+		if err != nil || slices.Contains(watched, dir) {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			a.Logger.Warn("Unable to watch", "path", dir, "err", err)
+			continue
+		}
+		watched = append(watched, dir)
+	}
+}
+
+// dependencies returns the local files that the doc for entity
+// depends on: the documents of the entity and of every schema
+// it (transitively) references.
+func (a *GenAction) dependencies(entity *jsonschema.Schema) []string {
+	deps := []string{}
+	visited := map[*jsonschema.Schema]bool{}
+	var walk func(schema *jsonschema.Schema)
+	walk = func(schema *jsonschema.Schema) {
+		if schema == nil || visited[schema] {
+			return
+		}
+		visited[schema] = true
+		if path, ok := a.localPath(schema.DocumentRoot().RetrievalURI); ok {
+			deps = append(deps, path)
+		}
+		// i.e. the document the schema was resolved from via a `$ref`.
+		walk(schema.Origin)
+		walk(schema.Items)
+		for _, subSchema := range schema.Properties {
+			walk(subSchema)
+		}
+		for _, subSchema := range schema.OneOf {
+			walk(subSchema)
+		}
+	}
+	walk(entity)
+	slices.Sort(deps)
+	return slices.Compact(deps)
+}
+
+// localPath returns the path of the local file that location
+// is loaded from (after mapping), if any.
+func (a *GenAction) localPath(location string) (string, bool) {
+	uri, err := url.Parse(a.Registry.MapURI(location))
+	if err != nil || (uri.Scheme != "" && uri.Scheme != "file") {
+		return "", false
+	}
+	path, err := filepath.Abs(filepath.FromSlash(uri.Path))
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/require"
+
+	"github.com/twelvelabs/schemadoc/internal/core"
+)
+
+func TestGenAction_regenerate_WhenFirstBuildFails(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "s", "root.schema.json")
+	require.NoError(os.MkdirAll(filepath.Dir(path), 0755))
+	broken := `{"title": "Root", "properties": {"a": {"$ref": "missing.schema.json"}}}`
+	require.NoError(os.WriteFile(path, []byte(broken), 0600))
+
+	a := &GenAction{
+		App:      core.NewTestApp(),
+		Format:   "markdown",
+		InPaths:  []string{path},
+		OutDir:   filepath.Join(dir, "out"),
+		Includes: []string{DefaultInclude},
+		Watch:    true,
+	}
+	require.NoError(a.setup())
+	deps, err := a.generate(nil)
+	require.Error(err)
+	require.Nil(deps)
+
+	// The input file is still watched.
+	watcher, err := fsnotify.NewWatcher()
+	require.NoError(err)
+	defer watcher.Close()
+	a.watchFiles(watcher, deps)
+	require.Contains(watcher.WatchList(), filepath.Dir(path))
+
+	// And fixing it regenerates everything.
+	require.NoError(os.WriteFile(path, []byte(`{"title": "Root", "type": "object"}`), 0600))
+	deps = a.regenerate(watcher, a.generate, deps, map[string]bool{path: true})
+	require.Equal([]string{path}, deps)
+	require.FileExists(filepath.Join(dir, "out", "root.md"))
+}
+
+// newDepsAction returns an action for a schema with cross-file $refs:
+// one relative to it, and one to a mapped URI.
+func newDepsAction(t *testing.T) (*GenAction, string) {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"s/root.schema.json": `{
+			"title": "Root",
+			"type": "object",
+			"properties": {
+				"address": {"$ref": "common/address.schema.json"},
+				"phone": {"$ref": "https://schemas.example.com/phone.schema.json"}
+			}
+		}`,
+		"s/common/address.schema.json": `{
+			"title": "Address",
+			"type": "object",
+			"properties": {"country": {"$ref": "country.schema.json"}}
+		}`,
+		"s/common/country.schema.json": `{"title": "Country", "type": "string"}`,
+		"mapped/phone.schema.json":     `{"title": "Phone", "type": "string"}`,
+		"s/notes.txt":                  `notes`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	a := &GenAction{
+		App:      core.NewTestApp(),
+		Format:   "markdown",
+		InPaths:  []string{filepath.Join(dir, "s", "root.schema.json")},
+		Includes: []string{DefaultInclude},
+		Mappings: map[string]string{"https://schemas.example.com/": filepath.Join(dir, "mapped") + "/"},
+		OutDir:   filepath.Join(dir, "out"),
+		Watch:    true,
+	}
+	require.NoError(t, a.setup())
+	require.NoError(t, a.discoverSchemas())
+	return a, dir
+}
+
+func TestGenAction_dependencies(t *testing.T) {
+	a, dir := newDepsAction(t)
+	docs, err := a.build()
+	require.NoError(t, err)
+	deps := map[string][]string{}
+	for _, doc := range docs {
+		deps[doc.Entity.EntityName()] = doc.Deps
+	}
+
+	path := func(name string) string {
+		return filepath.Join(dir, filepath.FromSlash(name))
+	}
+	tests := []struct {
+		entity string
+		want   []string
+	}{
+		{
+			entity: "Root",
+			// Every (transitively) referenced document, including mapped ones.
+			want: []string{
+				path("mapped/phone.schema.json"),
+				path("s/common/address.schema.json"),
+				path("s/common/country.schema.json"),
+				path("s/root.schema.json"),
+			},
+		},
+		{
+			entity: "Address",
+			want: []string{
+				path("s/common/address.schema.json"),
+				path("s/common/country.schema.json"),
+			},
+		},
+		{
+			entity: "Phone",
+			want:   []string{path("mapped/phone.schema.json")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.entity, func(t *testing.T) {
+			require.Equal(t, tt.want, deps[tt.entity])
+		})
+	}
+}
+
+func TestGenAction_localPath(t *testing.T) {
+	a, dir := newDepsAction(t)
+	abs, err := filepath.Abs("root.schema.json")
+	require.NoError(t, err)
+
+	tests := []struct {
+		location string
+		want     string
+		ok       bool
+	}{
+		{location: "root.schema.json", want: abs, ok: true},
+		{location: "file://" + filepath.ToSlash(abs), want: abs, ok: true},
+		{
+			location: "https://schemas.example.com/phone.schema.json",
+			want:     filepath.Join(dir, "mapped", "phone.schema.json"),
+			ok:       true,
+		},
+		{location: "https://example.com/root.schema.json", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			got, ok := a.localPath(tt.location)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGenAction_regenerate(t *testing.T) {
+	a, dir := newDepsAction(t)
+	watcher, err := fsnotify.NewWatcher()
+	require.NoError(t, err)
+	defer watcher.Close()
+
+	calls := []map[string]bool{}
+	generate := func(affected map[string]bool) ([]string, error) {
+		calls = append(calls, affected)
+		return a.generate(affected)
+	}
+	deps, err := a.generate(nil)
+	require.NoError(t, err)
+
+	// Changes to files that no doc depends on (in a watched dir) are ignored.
+	notes := filepath.Join(dir, "s", "notes.txt")
+	require.Equal(t, deps, a.regenerate(watcher, generate, deps, map[string]bool{notes: true}))
+	require.Empty(t, calls)
+
+	// Changes to dependencies regenerate the affected docs.
+	phone := filepath.Join(dir, "mapped", "phone.schema.json")
+	require.Equal(t, deps, a.regenerate(watcher, generate, deps, map[string]bool{phone: true}))
+	require.Equal(t, []map[string]bool{{phone: true}}, calls)
+}
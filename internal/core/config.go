@@ -3,7 +3,13 @@ package core
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 	"github.com/twelvelabs/termite/conf"
@@ -20,6 +26,124 @@ type Config struct {
 	Debug      bool   `yaml:"debug" env:"SCHEMADOC_DEBUG"`
 	Prompt     bool   `yaml:"prompt" env:"SCHEMADOC_PROMPT" default:"true"`
 	LogLevel   string `yaml:"log_level" env:"SCHEMADOC_LOG_LEVEL" default:"warn" validate:"oneof=debug info warn error fatal"` //nolint: lll
+
+	// Include and Exclude are doublestar glob patterns used to find
+	// schema files in input dirs (matched against the path relative
+	// to the input dir). Excluded dirs are not descended into.
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+
+	// Catalogs are SchemaStore-style catalogs used to resolve
+	// schema names and URLs to local files.
+	Catalogs []string `yaml:"catalogs"`
+	// Mappings rewrites schema URI prefixes to local files or dirs
+	// (or to other URIs) before they are loaded.
+	Mappings map[string]string `yaml:"mappings"`
+	HTTP     HTTPConfig        `yaml:"http"`
+
+	// Funcs are extra template funcs (by name) implemented by external commands.
+	Funcs map[string]FuncConfig `yaml:"funcs"`
+
+	// Targets are sets of `gen` options, all generated
+	// by a single `schemadoc gen` (or one by `--target`).
+	Targets []TargetConfig `yaml:"targets"`
+}
+
+// FuncConfig configures a template func implemented by an external command.
+type FuncConfig struct {
+	// Command is the command (and args) to run. The func args are written
+	// to its stdin as a JSON array, and its stdout is the func result.
+	Command []string `yaml:"command"`
+}
+
+// TargetConfig configures a `gen` target.
+// Unset fields fall back to the `gen` flags (or their defaults).
+type TargetConfig struct {
+	// Name is the name used to select the target (i.e. `--target`).
+	Name string `yaml:"name"`
+
+	In            []string `yaml:"in"`
+	Out           string   `yaml:"out"`
+	Format        string   `yaml:"format"`
+	Preset        string   `yaml:"preset"`
+	PresetBase    string   `yaml:"preset_base"`
+	Template      string   `yaml:"template"`
+	OutFile       string   `yaml:"outfile"`
+	Index         bool     `yaml:"index"`
+	IndexTemplate string   `yaml:"index_template"`
+	Single        bool     `yaml:"single"`
+	// Include and Exclude are added to the patterns in the config (and flags).
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+}
+
+// ValidateTargets returns an error if the targets are not (uniquely) named.
+// Their options are validated by `schemadoc gen` (as it validates flags).
+func (c *Config) ValidateTargets() error {
+	names := []string{}
+	for i, target := range c.Targets {
+		if target.Name == "" {
+			return fmt.Errorf("target %d has no name", i+1)
+		}
+		if slices.Contains(names, target.Name) {
+			return fmt.Errorf("duplicate target %q", target.Name)
+		}
+		names = append(names, target.Name)
+	}
+	return nil
+}
+
+// HTTPConfig configures how remote schemas are fetched.
+type HTTPConfig struct {
+	CAFile  string                    `yaml:"ca_file" env:"SCHEMADOC_HTTP_CA_FILE"`
+	Hosts   map[string]HTTPHostConfig `yaml:"hosts"`
+	Proxy   string                    `yaml:"proxy" env:"SCHEMADOC_HTTP_PROXY"`
+	Retries int                       `yaml:"retries" env:"SCHEMADOC_HTTP_RETRIES" default:"2" validate:"gte=0"`
+	Timeout time.Duration             `yaml:"timeout" env:"SCHEMADOC_HTTP_TIMEOUT" default:"30s"`
+}
+
+// HTTPHostConfig contains the settings for requests to a single host.
+// Values may reference environment variables (`$VAR` or `${VAR}`)
+// so that secrets do not need to be committed.
+type HTTPHostConfig struct {
+	// Headers are added to every request to the host.
+	Headers map[string]string `yaml:"headers"`
+	// Token is sent as a bearer token in the `Authorization` header.
+	Token string `yaml:"token"`
+}
+
+// RequestHeaders returns the (env expanded) headers
+// to send with requests to the host.
+func (c HTTPHostConfig) RequestHeaders() http.Header {
+	headers := http.Header{}
+	for key, value := range c.Headers {
+		headers.Set(key, os.ExpandEnv(value))
+	}
+	if token := os.ExpandEnv(c.Token); token != "" {
+		headers.Set("Authorization", "Bearer "+token)
+	}
+	return headers
+}
+
+var uriSchemeRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]+:`)
+
+// ResolvePath returns path (from the config file) resolved relative to
+// the dir of the config file, rather than the working dir.
+// Absolute paths and URIs are returned as is.
+func (c *Config) ResolvePath(path string) string {
+	dir := filepath.Dir(c.ConfigPath)
+	if path == "" || c.ConfigPath == "" || dir == "." {
+		return path
+	}
+	if filepath.IsAbs(path) || uriSchemeRegexp.MatchString(path) {
+		return path
+	}
+	resolved := filepath.Join(dir, path)
+	// Keep the trailing slash of dir mappings.
+	if strings.HasSuffix(path, "/") {
+		resolved += "/"
+	}
+	return resolved
 }
 
 // NewTestConfig returns a new Config for unit tests
@@ -35,6 +159,9 @@ func NewConfigFromPath(path string) (*Config, error) {
 		return nil, fmt.Errorf("config load: %w", err)
 	}
 	config.ConfigPath = path
+	if err := config.ValidateTargets(); err != nil {
+		return nil, fmt.Errorf("config load: targets: %w", err)
+	}
 	return config, nil
 }
 
@@ -1,8 +1,10 @@
 package core
 
 import (
+	"net/http"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -28,6 +30,42 @@ func TestNewConfigFromPath(t *testing.T) {
 				Debug:      false,
 				Prompt:     true,
 				LogLevel:   "warn",
+				Include:    []string{"**/*.schema.json", "**/*.schema.yaml"},
+				Exclude:    []string{"vendor/**"},
+				Catalogs:   []string{"schemas/catalog.json"},
+				Mappings: map[string]string{
+					"https://schemas.example.com/common/": "schemas/common/",
+				},
+				HTTP: HTTPConfig{
+					Hosts: map[string]HTTPHostConfig{
+						"schemas.example.com": {
+							Token: "${SCHEMA_REGISTRY_TOKEN}",
+						},
+					},
+					Retries: 2,
+					Timeout: 10 * time.Second,
+				},
+				Funcs: map[string]FuncConfig{
+					"envVarLink": {
+						Command: []string{"./scripts/env-var-link.sh", "--registry", "https://env.example.com"},
+					},
+				},
+				Targets: []TargetConfig{
+					{
+						Name: "api",
+						In:   []string{"schemas/api"},
+						Out:  "docs/api",
+					},
+					{
+						Name:     "site",
+						In:       []string{"schemas"},
+						Out:      "site/content/schemas",
+						Format:   "markdown",
+						Preset:   "hugo",
+						Template: "templates",
+						Exclude:  []string{"**/internal/**"},
+					},
+				},
 			},
 			assertion: assert.NoError,
 		},
@@ -39,6 +77,16 @@ func TestNewConfigFromPath(t *testing.T) {
 			want:      nil,
 			assertion: assert.Error,
 		},
+		{
+			name: "should return error if a target is invalid",
+			args: args{
+				path: filepath.Join("testdata", "config", "invalid_targets.yaml"),
+			},
+			want: nil,
+			assertion: func(t assert.TestingT, err error, _ ...any) bool {
+				return assert.ErrorContains(t, err, `targets: duplicate target "site"`)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -49,6 +97,75 @@ func TestNewConfigFromPath(t *testing.T) {
 	}
 }
 
+func TestConfig_ResolvePath(t *testing.T) {
+	config := &Config{ConfigPath: filepath.Join("project", "config", ".schemadoc.yaml")}
+	assert.Equal(t, filepath.Join("project", "config", "schemas"), config.ResolvePath("schemas"))
+	assert.Equal(t, filepath.Join("project", "schemas")+"/", config.ResolvePath("../schemas/"))
+	assert.Equal(t, "/abs/schemas", config.ResolvePath("/abs/schemas"))
+	assert.Equal(t, "https://example.com/schemas/", config.ResolvePath("https://example.com/schemas/"))
+	assert.Equal(t, "mem:///schemas/", config.ResolvePath("mem:///schemas/"))
+	assert.Empty(t, config.ResolvePath(""))
+
+	// Paths are unchanged when the config file is in the working dir.
+	config = &Config{ConfigPath: ConfigPathDefault}
+	assert.Equal(t, "schemas/", config.ResolvePath("schemas/"))
+}
+
+func TestConfig_ValidateTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		targets []TargetConfig
+		err     string
+	}{
+		{
+			name: "should accept named targets",
+			targets: []TargetConfig{
+				{Name: "api", Index: true},
+				// Options are validated by `gen`.
+				{Name: "site", Preset: "hugo", Index: true},
+			},
+		},
+		{
+			name:    "should reject targets without a name",
+			targets: []TargetConfig{{Out: "docs"}},
+			err:     "target 1 has no name",
+		},
+		{
+			name:    "should reject duplicate names",
+			targets: []TargetConfig{{Name: "api"}, {Name: "api"}},
+			err:     `duplicate target "api"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Config{Targets: tt.targets}).ValidateTargets()
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestHTTPHostConfig_RequestHeaders(t *testing.T) {
+	t.Setenv("SCHEMA_REGISTRY_TOKEN", "secret")
+
+	config := HTTPHostConfig{}
+	assert.Equal(t, http.Header{}, config.RequestHeaders())
+
+	config = HTTPHostConfig{
+		Headers: map[string]string{
+			"x-api-key": "$SCHEMA_REGISTRY_TOKEN",
+		},
+		Token: "${SCHEMA_REGISTRY_TOKEN}",
+	}
+	assert.Equal(t, http.Header{
+		"Authorization": []string{"Bearer secret"},
+		"X-Api-Key":     []string{"secret"},
+	}, config.RequestHeaders())
+}
+
 func TestConfigPath(t *testing.T) {
 	type args struct {
 		args []string
@@ -0,0 +1,79 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// CommandFuncEnv is the env var set to the func name when running a [CommandFunc].
+const CommandFuncEnv = "SCHEMADOC_FUNC"
+
+var funcNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateFuncName returns an error if name can not be used
+// as a template func name (i.e. is not an identifier).
+func ValidateFuncName(name string) error {
+	if !funcNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid func name %q (must be an identifier)", name)
+	}
+	return nil
+}
+
+// CommandFunc returns a template func that runs an external command.
+// The func args are written to the command's stdin as a JSON array,
+// and its stdout (sans trailing newline) is returned.
+// Results are cached by args, so commands should be deterministic.
+// The command runs in dir (the working dir if empty), which a relative
+// command path (i.e. `./scripts/func.sh`) is also relative to.
+func CommandFunc(name string, command []string, dir string) (func(args ...any) (string, error), error) {
+	if err := ValidateFuncName(name); err != nil {
+		return nil, err
+	}
+	if len(command) == 0 || command[0] == "" {
+		return nil, fmt.Errorf("func %q: command is required", name)
+	}
+
+	cache := map[string]string{}
+	mu := sync.Mutex{}
+	return func(args ...any) (string, error) {
+		if args == nil {
+			args = []any{}
+		}
+		input, err := json.Marshal(args)
+		if err != nil {
+			return "", fmt.Errorf("func %q: %w", name, err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if output, ok := cache[string(input)]; ok {
+			return output, nil
+		}
+
+		stdout := bytes.Buffer{}
+		stderr := bytes.Buffer{}
+		cmd := exec.Command(command[0], command[1:]...) //nolint: gosec
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), CommandFuncEnv+"="+name)
+		cmd.Stdin = bytes.NewReader(input)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				err = errors.Join(err, errors.New(msg))
+			}
+			return "", fmt.Errorf("func %q: %w", name, err)
+		}
+
+		output := strings.TrimSuffix(stdout.String(), "\n")
+		cache[string(input)] = output
+		return output, nil
+	}, nil
+}
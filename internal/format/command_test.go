@@ -0,0 +1,58 @@
+package format
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandFunc(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "calls")
+	fn, err := CommandFunc("envLink", []string{
+		"sh", "-c", `echo call >> "$0"; printf '%s:' "$` + CommandFuncEnv + `"; cat; echo`, log,
+	}, "")
+	require.NoError(t, err)
+
+	tpl := template.Must(template.New("test").Funcs(template.FuncMap{"envLink": fn}).Parse(
+		`{{ envLink "HOME" 1 }}|{{ envLink "HOME" 1 }}|{{ envLink }}`,
+	))
+	buf := strings.Builder{}
+	require.NoError(t, tpl.Execute(&buf, nil))
+	require.Equal(t, `envLink:["HOME",1]|envLink:["HOME",1]|envLink:[]`, buf.String())
+
+	// Repeated calls are cached.
+	calls, err := os.ReadFile(log)
+	require.NoError(t, err)
+	require.Equal(t, 2, strings.Count(string(calls), "call"))
+}
+
+func TestCommandFunc_Error(t *testing.T) {
+	fn, err := CommandFunc("fail", []string{"sh", "-c", "echo oops >&2; exit 3"}, "")
+	require.NoError(t, err)
+	_, err = fn("x")
+	require.ErrorContains(t, err, `func "fail": exit status 3`)
+	require.ErrorContains(t, err, "oops")
+
+	_, err = CommandFunc("empty", nil, "")
+	require.ErrorContains(t, err, `func "empty": command is required`)
+	_, err = CommandFunc("env-link", []string{"true"}, "")
+	require.ErrorContains(t, err, `invalid func name "env-link"`)
+}
+
+func TestCommandFunc_WhenDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "scripts"), 0700))
+	script := filepath.Join(dir, "scripts", "func.sh")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\nbasename \"$PWD\"\n"), 0700)) //nolint: gosec
+
+	// Relative commands (and the commands themselves) run in dir.
+	fn, err := CommandFunc("dirName", []string{"./scripts/func.sh"}, dir)
+	require.NoError(t, err)
+	output, err := fn()
+	require.NoError(t, err)
+	require.Equal(t, filepath.Base(dir), output)
+}
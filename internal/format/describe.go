@@ -0,0 +1,104 @@
+package format
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// DescribeFuncs returns the signature of each func in funcs
+// (e.g. `toHTML(string) (string, error)`), sorted by name.
+func DescribeFuncs(funcs template.FuncMap) []string {
+	lines := []string{}
+	for name, fn := range funcs {
+		lines = append(lines, name+signature(reflect.TypeOf(fn), 0))
+	}
+	slices.Sort(lines)
+	return lines
+}
+
+// DescribeType returns a description of the exported fields and methods
+// of the struct type of v (i.e. what templates can use).
+func DescribeType(v any) string {
+	ptr := reflect.TypeOf(v)
+	typ := ptr
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	} else {
+		ptr = reflect.PointerTo(typ)
+	}
+
+	buf := strings.Builder{}
+	fmt.Fprintf(&buf, "%s\n", typ.String())
+	if typ.Kind() == reflect.Struct {
+		fields := []string{}
+		collectFields(typ, &fields)
+		if len(fields) > 0 {
+			buf.WriteString("  Fields:\n")
+			for _, field := range fields {
+				fmt.Fprintf(&buf, "    %s\n", field)
+			}
+		}
+	}
+	methods := []string{}
+	for i := range ptr.NumMethod() {
+		method := ptr.Method(i)
+		// Skip the receiver.
+		methods = append(methods, method.Name+signature(method.Type, 1))
+	}
+	if len(methods) > 0 {
+		buf.WriteString("  Methods:\n")
+		for _, method := range methods {
+			fmt.Fprintf(&buf, "    %s\n", method)
+		}
+	}
+	return buf.String()
+}
+
+// collectFields appends the exported fields of typ to fields,
+// promoting the fields of embedded structs.
+func collectFields(typ reflect.Type, fields *[]string) {
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			collectFields(field.Type, fields)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		*fields = append(*fields, field.Name+" "+field.Type.String())
+	}
+}
+
+// signature returns the signature of the func type fn,
+// skipping the first skip params.
+func signature(fn reflect.Type, skip int) string {
+	if fn.Kind() != reflect.Func {
+		return " " + fn.String()
+	}
+	params := []string{}
+	for i := skip; i < fn.NumIn(); i++ {
+		param := fn.In(i).String()
+		if fn.IsVariadic() && i == fn.NumIn()-1 {
+			param = "..." + fn.In(i).Elem().String()
+		}
+		params = append(params, param)
+	}
+	results := []string{}
+	for i := range fn.NumOut() {
+		results = append(results, fn.Out(i).String())
+	}
+
+	sig := "(" + strings.Join(params, ", ") + ")"
+	switch len(results) {
+	case 0:
+	case 1:
+		sig += " " + results[0]
+	default:
+		sig += " (" + strings.Join(results, ", ") + ")"
+	}
+	return sig
+}
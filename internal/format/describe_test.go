@@ -0,0 +1,55 @@
+package format
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/require"
+)
+
+type describeExample struct {
+	describeEmbedded
+
+	Name    string
+	Tags    []string
+	private bool //nolint: unused
+}
+
+type describeEmbedded struct {
+	ID int
+}
+
+func (e *describeExample) Greet(greeting string, names ...string) string {
+	return greeting + " " + strings.Join(names, ", ")
+}
+
+func (e describeExample) Valid() (bool, error) {
+	return true, nil
+}
+
+func TestDescribeFuncs(t *testing.T) {
+	require.Equal(t, []string{
+		"join(string, ...string) string",
+		"lower(string) string",
+		"now() (int, error)",
+	}, DescribeFuncs(template.FuncMap{
+		"lower": strings.ToLower,
+		"join":  func(sep string, s ...string) string { return strings.Join(s, sep) },
+		"now":   func() (int, error) { return 0, nil },
+	}))
+}
+
+func TestDescribeType(t *testing.T) {
+	expected := `format.describeExample
+  Fields:
+    ID int
+    Name string
+    Tags []string
+  Methods:
+    Greet(string, ...string) string
+    Valid() (bool, error)
+`
+	require.Equal(t, expected, DescribeType(&describeExample{}))
+	require.Equal(t, expected, DescribeType(describeExample{}))
+}
@@ -0,0 +1,80 @@
+package format
+
+import (
+	"encoding/json"
+	"html"
+	"strings"
+	"text/template"
+)
+
+// FuncMap returns the escaping helpers (and `modelJSON`) for use in templates.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"escapeAsciiDoc": EscapeAsciiDoc,
+		"escapeHTML":     EscapeHTML,
+		"escapeJSON":     EscapeJSON,
+		"escapeMarkdown": EscapeMarkdown,
+		"escapeRST":      EscapeRST,
+		"modelJSON":      ModelJSON,
+	}
+}
+
+var (
+	asciiDocReplacer = strings.NewReplacer(
+		`|`, `\|`,
+		`*`, `\*`,
+		`_`, `\_`,
+		"`", "\\`",
+		`#`, `\#`,
+		`{`, `\{`,
+		`[`, `\[`,
+		`<<`, `\<<`,
+	)
+	markdownReplacer = strings.NewReplacer(
+		`\`, `\\`,
+		"`", "\\`",
+		`*`, `\*`,
+		`_`, `\_`,
+		`[`, `\[`,
+		`]`, `\]`,
+		`<`, `&lt;`,
+		`>`, `&gt;`,
+		`|`, `&#124;`,
+	)
+	rstReplacer = strings.NewReplacer(
+		`\`, `\\`,
+		"`", "\\`",
+		`*`, `\*`,
+		`_`, `\_`,
+		`|`, `\|`,
+	)
+)
+
+// EscapeAsciiDoc escapes s for use as inline AsciiDoc text
+// (including within table cells).
+func EscapeAsciiDoc(s string) string {
+	return asciiDocReplacer.Replace(s)
+}
+
+// EscapeHTML escapes s for use as HTML text or attribute values.
+func EscapeHTML(s string) string {
+	return html.EscapeString(s)
+}
+
+// EscapeJSON returns s as a (quoted) JSON string.
+func EscapeJSON(s string) string {
+	// Marshalling a string can not fail.
+	buf, _ := json.Marshal(s)
+	return string(buf)
+}
+
+// EscapeMarkdown escapes s for use as inline Markdown text
+// (including within table cells).
+func EscapeMarkdown(s string) string {
+	return markdownReplacer.Replace(s)
+}
+
+// EscapeRST escapes s for use as inline reStructuredText.
+func EscapeRST(s string) string {
+	return rstReplacer.Replace(s)
+}
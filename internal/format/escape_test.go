@@ -0,0 +1,27 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		name   string
+		escape func(string) string
+		input  string
+		want   string
+	}{
+		{"asciidoc", EscapeAsciiDoc, "a|b *c* <<d>>", `a\|b \*c\* \<<d>>`},
+		{"html", EscapeHTML, `<a href="x">&</a>`, "&lt;a href=&#34;x&#34;&gt;&amp;&lt;/a&gt;"},
+		{"json", EscapeJSON, "say \"hi\"\n", `"say \"hi\"\n"`},
+		{"markdown", EscapeMarkdown, "a|b *c* <d>", `a&#124;b \*c\* &lt;d&gt;`},
+		{"rst", EscapeRST, "a|b *c* `d`", "a\\|b \\*c\\* \\`d\\`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.escape(tt.input))
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package format
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"sync"
+	"text/template"
+
+	"github.com/twelvelabs/schemadoc/internal/jsonschema"
+)
+
+// Format is a built-in set of templates for an output format.
+type Format struct {
+	// Name is the name used to select the format (i.e. `--format`).
+	Name string
+	// Extension is the default extension of generated files.
+	Extension string
+	// Template is the path of the entity template in [jsonschema.Templates].
+	Template string
+	// IndexTemplate is the path of the index template in [jsonschema.Templates].
+	IndexTemplate string
+	// HTMLLayout is true if the template renders Markdown that must be
+	// converted to HTML and wrapped in the HTML layout.
+	HTMLLayout bool
+	// Comment is the format string of a (single line) comment, used to
+	// separate documents written to stdout. The verb is the comment text.
+	// Empty if the format has no comment syntax.
+	Comment string
+}
+
+// Parse parses the entity template using funcs.
+func (f *Format) Parse(funcs template.FuncMap) (*template.Template, error) {
+	return parseTemplate(f.Template, funcs)
+}
+
+// ParseIndex parses the index template using funcs.
+func (f *Format) ParseIndex(funcs template.FuncMap) (*template.Template, error) {
+	return parseTemplate(f.IndexTemplate, funcs)
+}
+
+// IndexPath returns the path of the index page.
+func (f *Format) IndexPath() string {
+	return IndexName + f.Extension
+}
+
+func parseTemplate(name string, funcs template.FuncMap) (*template.Template, error) {
+	return template.New(path.Base(name)).
+		Funcs(funcs).
+		ParseFS(jsonschema.Templates, name)
+}
+
+// DefaultOutFile returns the default filename pattern for generated files.
+func (f *Format) DefaultOutFile() string {
+	return "{{ .EntityName | underscore }}" + f.Extension
+}
+
+var (
+	formats = map[string]*Format{}
+	mu      sync.RWMutex
+)
+
+func init() {
+	Register(&Format{
+		Name:          Markdown,
+		Extension:     ".md",
+		Template:      "templates/markdown.tpl.md",
+		IndexTemplate: "templates/index.tpl.md",
+		Comment:       "<!-- %s -->",
+	})
+	Register(&Format{
+		Name:          HTML,
+		Extension:     ".html",
+		Template:      "templates/markdown.tpl.md",
+		IndexTemplate: "templates/index.tpl.md",
+		HTMLLayout:    true,
+		Comment:       "<!-- %s -->",
+	})
+	Register(&Format{
+		Name:          AsciiDoc,
+		Extension:     ".adoc",
+		Template:      "templates/asciidoc.tpl.adoc",
+		IndexTemplate: "templates/index.tpl.adoc",
+		Comment:       "// %s",
+	})
+	Register(&Format{
+		Name:          ReStructuredText,
+		Extension:     ".rst",
+		Template:      "templates/restructuredtext.tpl.rst",
+		IndexTemplate: "templates/index.tpl.rst",
+		Comment:       ".. %s",
+	})
+	Register(&Format{
+		Name:          JSON,
+		Extension:     ".json",
+		Template:      "templates/json.tpl.json",
+		IndexTemplate: "templates/index.tpl.json",
+	})
+}
+
+// Names of the built-in formats.
+const (
+	AsciiDoc         = "asciidoc"
+	HTML             = "html"
+	JSON             = "json"
+	Markdown         = "markdown"
+	ReStructuredText = "restructuredtext"
+)
+
+// Register registers format, replacing any format with the same name.
+func Register(format *Format) {
+	mu.Lock()
+	defer mu.Unlock()
+	formats[format.Name] = format
+}
+
+// Lookup returns the format registered with name.
+func Lookup(name string) (*Format, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	format, ok := formats[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q (must be one of %v)", name, names())
+	}
+	return format, nil
+}
+
+// Names returns the (sorted) names of the registered formats.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return names()
+}
+
+func names() []string {
+	return slices.Sorted(maps.Keys(formats))
+}
@@ -0,0 +1,83 @@
+package format
+
+import (
+	"bytes"
+	"maps"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/require"
+	"github.com/twelvelabs/termite/render"
+	stripmd "github.com/writeas/go-strip-markdown"
+
+	"github.com/twelvelabs/schemadoc/internal/jsonschema"
+	"github.com/twelvelabs/schemadoc/internal/markdown"
+)
+
+func TestLookup(t *testing.T) {
+	require := require.New(t)
+
+	f, err := Lookup(Markdown)
+	require.NoError(err)
+	require.Equal(".md", f.Extension)
+	require.Equal("{{ .EntityName | underscore }}.md", f.DefaultOutFile())
+	require.False(f.HTMLLayout)
+
+	f, err = Lookup(HTML)
+	require.NoError(err)
+	require.Equal("{{ .EntityName | underscore }}.html", f.DefaultOutFile())
+	require.True(f.HTMLLayout)
+
+	_, err = Lookup("nope")
+	require.ErrorContains(err, `unknown format "nope"`)
+}
+
+func TestNames(t *testing.T) {
+	require.Equal(t, []string{
+		AsciiDoc, HTML, JSON, Markdown, ReStructuredText,
+	}, Names())
+}
+
+// testFuncs mirrors the funcs registered by the gen command.
+func testFuncs() template.FuncMap {
+	funcs := maps.Clone(render.FuncMap)
+	maps.Copy(funcs, FuncMap())
+	funcs["toHTML"] = markdown.ToHTMLString
+	funcs["wrapCode"] = markdown.WrapCode
+	funcs["firstSentence"] = markdown.FirstSentence
+	funcs["stripMarkdown"] = stripmd.Strip
+	return funcs
+}
+
+func TestFormat_Parse(t *testing.T) {
+	funcs := testFuncs()
+	schema := &jsonschema.Schema{
+		Title:       "Example",
+		Description: "An *example* schema.",
+		Properties: map[string]*jsonschema.Schema{
+			"name": {
+				Key:         "name",
+				Types:       []jsonschema.Type{jsonschema.TypeString},
+				Description: "The name.",
+			},
+		},
+	}
+	schema.Properties["name"].Parent = schema
+
+	for _, name := range Names() {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			f, err := Lookup(name)
+			require.NoError(err)
+
+			tpl, err := f.Parse(funcs)
+			require.NoError(err)
+
+			buf := bytes.Buffer{}
+			require.NoError(tpl.Execute(&buf, schema))
+			require.Contains(buf.String(), "Example")
+			require.Contains(buf.String(), "name")
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package format
+
+import (
+	"strings"
+
+	"github.com/twelvelabs/schemadoc/internal/jsonschema"
+	"github.com/twelvelabs/schemadoc/internal/markdown"
+)
+
+// IndexName is the filename (sans extension) of the generated index page.
+const IndexName = "index"
+
+// Index is the data passed to index templates:
+// every generated entity, grouped by the schema document defining it.
+type Index struct {
+	// Version is the [ModelVersion] (for `--format json`).
+	Version int `json:"version"`
+	// Title is the index page title.
+	Title string `json:"title"`
+	// Groups are the source schemas, in generation order.
+	Groups []*IndexGroup `json:"groups"`
+	// Contents is true if the index is the table of contents of a single file
+	// documenting every entity. Entries link to in-page anchors, and are not
+	// grouped by source schema (i.e. are all in a single, unnamed group).
+	Contents bool `json:"-"`
+}
+
+// IndexGroup is a source schema document and the entities it defines.
+type IndexGroup struct {
+	// Name is the name of the schema (i.e. its entity name).
+	Name string `json:"name"`
+	// Source is the URI (or path) the schema was loaded from,
+	// with local paths relative to its input dir (see [jsonschema.Schema.SourceURI]).
+	Source string `json:"source"`
+	// Entries are the entities defined by the schema, in generation order.
+	Entries []*IndexEntry `json:"entries"`
+
+	// uri is the retrieval URI of the schema (which, unlike Source,
+	// is distinct for documents with the same path in different input dirs).
+	uri string
+}
+
+// IndexEntry is a generated entity.
+type IndexEntry struct {
+	// Name is the entity name.
+	Name string `json:"name"`
+	// Link is the path to the entity page, relative to the index page.
+	Link string `json:"link"`
+	// Summary is the first sentence of the entity description (in Markdown).
+	Summary string `json:"summary,omitempty"`
+	// Deprecated is true if the entity is deprecated.
+	Deprecated bool `json:"deprecated,omitempty"`
+	// Schema is the entity schema.
+	Schema *jsonschema.Schema `json:"-"`
+}
+
+// Add adds an entry for entity (whose page is at link) to the index,
+// in the group for the document defining it.
+func (idx *Index) Add(entity *jsonschema.Schema, link string) {
+	root := entity.DocumentRoot()
+	uri, source := root.RetrievalURI, root.SourceURI()
+	if idx.Contents {
+		uri, source = "", ""
+	}
+	var group *IndexGroup
+	for _, g := range idx.Groups {
+		if g.uri == uri {
+			group = g
+			break
+		}
+	}
+	if group == nil {
+		name := root.EntityName()
+		if name == "" || idx.Contents {
+			name = source
+		}
+		group = &IndexGroup{Name: name, Source: source, uri: uri}
+		idx.Groups = append(idx.Groups, group)
+	}
+
+	summary := markdown.FirstSentence(entity.DescriptionMarkdown())
+	group.Entries = append(group.Entries, &IndexEntry{
+		Name:       entity.EntityName(),
+		Link:       link,
+		Summary:    strings.Join(strings.Fields(summary), " "),
+		Deprecated: entity.Deprecated,
+		Schema:     entity,
+	})
+}
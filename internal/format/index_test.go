@@ -0,0 +1,106 @@
+package format
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/twelvelabs/schemadoc/internal/jsonschema"
+)
+
+func TestIndex_Add(t *testing.T) {
+	require := require.New(t)
+
+	widget := &jsonschema.Schema{
+		Title:        "Widget",
+		Description:  "A widget.\nWith parts.",
+		RetrievalURI: "widget.schema.json",
+	}
+	part := &jsonschema.Schema{
+		Key:         "part",
+		Description: "A part\nof a widget. Or two.",
+		Deprecated:  true,
+		Parent:      widget,
+	}
+	gear := &jsonschema.Schema{
+		Key:          "gear",
+		RetrievalURI: "/work/schemas/common/gear.schema.json",
+		SourceDir:    "/work/schemas",
+	}
+
+	index := &Index{Title: "Schemas"}
+	index.Add(widget, "widget.md")
+	index.Add(gear, "gear.md")
+	index.Add(part, "part.md")
+
+	require.Len(index.Groups, 2)
+	require.Equal("Widget", index.Groups[0].Name)
+	require.Equal([]*IndexEntry{
+		{Name: "Widget", Link: "widget.md", Summary: "A widget.", Schema: widget},
+		{Name: "Part", Link: "part.md", Summary: "A part of a widget.", Deprecated: true, Schema: part},
+	}, index.Groups[0].Entries)
+	require.Equal("Gear", index.Groups[1].Name)
+	// Relative to the input dir (so the same in every checkout).
+	require.Equal("common/gear.schema.json", index.Groups[1].Source)
+
+	f, err := Lookup(Markdown)
+	require.NoError(err)
+	tpl, err := f.ParseIndex(testFuncs())
+	require.NoError(err)
+	buf := bytes.Buffer{}
+	require.NoError(tpl.Execute(&buf, index))
+	require.Equal(`# Schemas
+
+## Widget
+
+- [Widget](widget.md): A widget.
+- [Part](part.md) **(deprecated)**: A part of a widget.
+
+## Gear
+
+- [Gear](gear.md)
+`, buf.String())
+}
+
+func TestIndex_Add_Contents(t *testing.T) {
+	require := require.New(t)
+
+	index := &Index{Title: "Contents", Contents: true}
+	index.Add(&jsonschema.Schema{Title: "Widget", RetrievalURI: "widget.schema.json"}, "#widget")
+	index.Add(&jsonschema.Schema{Title: "Gear", RetrievalURI: "gear.schema.json"}, "#gear")
+
+	// Entries are not grouped.
+	require.Len(index.Groups, 1)
+	require.Equal("", index.Groups[0].Name)
+	require.Len(index.Groups[0].Entries, 2)
+
+	f, err := Lookup(Markdown)
+	require.NoError(err)
+	tpl, err := f.ParseIndex(testFuncs())
+	require.NoError(err)
+	buf := bytes.Buffer{}
+	require.NoError(tpl.Execute(&buf, index))
+	require.Equal("# Contents\n\n- [Widget](#widget)\n- [Gear](#gear)\n", buf.String())
+}
+
+func TestIndex_Markdown_Escapes(t *testing.T) {
+	require := require.New(t)
+
+	index := &Index{
+		Title: "my_docs",
+		Groups: []*IndexGroup{
+			{
+				Name:    "Foo*Bar",
+				Entries: []*IndexEntry{{Name: "[Baz]", Link: "baz.md"}},
+			},
+		},
+	}
+	f, err := Lookup(Markdown)
+	require.NoError(err)
+	tpl, err := f.ParseIndex(testFuncs())
+	require.NoError(err)
+	buf := bytes.Buffer{}
+	require.NoError(tpl.Execute(&buf, index))
+	require.Equal("# my\\_docs\n\n## Foo\\*Bar\n\n- [\\[Baz\\]](baz.md)\n", buf.String())
+}
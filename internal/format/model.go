@@ -0,0 +1,264 @@
+package format
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/twelvelabs/schemadoc/internal/jsonschema"
+)
+
+// ModelVersion is the version of the [Model] shape.
+// It is incremented whenever a field is removed or changes meaning
+// (adding fields is not a breaking change).
+const ModelVersion = 1
+
+// Model is the machine-readable documentation model for an entity
+// (i.e. a root schema or definition), as rendered by `--format json`.
+type Model struct {
+	// Version is the [ModelVersion] the model was generated with.
+	Version int `json:"version"`
+	// Name is the entity name (i.e. the page heading).
+	Name string `json:"name"`
+	// URI uniquely identifies the entity (see [jsonschema.Schema.EntityURI]).
+	URI string `json:"uri"`
+	// Path is the path of the generated file, relative to the output dir.
+	Path string `json:"path"`
+	// Source is the location of the schema the entity is defined by.
+	Source Source `json:"source"`
+
+	Definition
+
+	// Properties are the properties of an object entity, sorted by name.
+	Properties []Property `json:"properties"`
+	// Variants link to the schemas of a `oneOf` entity.
+	Variants []Link `json:"variants,omitempty"`
+}
+
+// Property is a property of a [Model].
+type Property struct {
+	// Name is the property key.
+	Name string `json:"name"`
+	// Required is true if the parent object requires the property.
+	Required bool `json:"required"`
+	// Source is the location the property is declared at.
+	Source Source `json:"source"`
+
+	Definition
+}
+
+// Definition holds the fields shared by entities and properties.
+type Definition struct {
+	// Type is the computed type string (e.g. `string | Widget[]`).
+	Type string `json:"type"`
+	// Types are the JSON schema types.
+	Types []string `json:"types"`
+	// Description is the description, formatted as Markdown.
+	Description string `json:"description,omitempty"`
+	// Deprecated, ReadOnly and WriteOnly are the JSON schema annotations.
+	Deprecated bool `json:"deprecated,omitempty"`
+	ReadOnly   bool `json:"readOnly,omitempty"`
+	WriteOnly  bool `json:"writeOnly,omitempty"`
+	// Default is the default value (if any).
+	Default any `json:"default,omitempty"`
+	// Enum is the list of allowed values (if any).
+	Enum []any `json:"enum,omitempty"`
+	// Examples are example values (if any).
+	Examples []any `json:"examples,omitempty"`
+	// Constraints are the validation keywords that constrain the value.
+	Constraints Constraints `json:"constraints,omitempty"`
+	// Links link to the entities referenced by [Definition.Type].
+	Links []Link `json:"links,omitempty"`
+}
+
+// Constraints are the validation keywords of a schema, keyed by
+// JSON schema keyword (e.g. `minLength`). Unset keywords are omitted.
+type Constraints map[string]any
+
+// Link is a link to the documentation of an entity.
+type Link struct {
+	// Name is the entity name.
+	Name string `json:"name"`
+	// URI uniquely identifies the entity (see [Model.URI]).
+	URI string `json:"uri"`
+	// Href is the link to the entity, relative to the linking page.
+	Href string `json:"href"`
+}
+
+// Source is the location of a schema.
+type Source struct {
+	// URI is the URI the schema document was loaded from
+	// (or the path, relative to the input dir, for local files).
+	URI string `json:"uri"`
+	// Pointer is the JSON pointer to the schema within the document
+	// (empty for the document root).
+	Pointer string `json:"pointer"`
+}
+
+// NewModel returns the documentation model for entity.
+func NewModel(entity *jsonschema.Schema) *Model {
+	model := &Model{
+		Version:    ModelVersion,
+		Name:       entity.EntityName(),
+		URI:        entity.EntityURI(),
+		Path:       entity.GenPath(),
+		Source:     newSource(entity),
+		Definition: newDefinition(entity),
+		Properties: []Property{},
+	}
+	// An object entity's type links to itself, which is of no use.
+	model.Links = slices.DeleteFunc(model.Links, func(link Link) bool {
+		return link.URI == model.URI
+	})
+	if len(model.Links) == 0 {
+		model.Links = nil
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(entity.Properties)) {
+		prop := entity.Properties[key]
+		// Where the property is declared (rather than the target of its $ref).
+		source := model.Source
+		source.Pointer += "/properties/" + pointerEscaper.Replace(key)
+		model.Properties = append(model.Properties, Property{
+			Name:       key,
+			Required:   entity.RequiredKey(key),
+			Source:     source,
+			Definition: newDefinition(prop),
+		})
+	}
+	for _, variant := range entity.OneOf {
+		model.Variants = append(model.Variants, newLink(variant))
+	}
+	return model
+}
+
+// JSON returns the model serialized as indented JSON.
+func (m *Model) JSON() (string, error) {
+	buf, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(buf) + "\n", nil
+}
+
+// ModelJSON returns the documentation model for entity serialized as JSON.
+// Exposed to templates as `modelJSON`.
+func ModelJSON(entity *jsonschema.Schema) (string, error) {
+	return NewModel(entity).JSON()
+}
+
+func newDefinition(s *jsonschema.Schema) Definition {
+	def := Definition{
+		Type:        s.TypeInfoFormat("%[1]s", " | "),
+		Types:       []string{},
+		Description: s.DescriptionMarkdown(),
+		Deprecated:  s.Deprecated,
+		ReadOnly:    s.ReadOnly,
+		WriteOnly:   s.WriteOnly,
+		Default:     s.Default.Value(),
+		Constraints: newConstraints(s),
+		Links:       newLinks(s),
+	}
+	for _, t := range s.Types {
+		def.Types = append(def.Types, string(t))
+	}
+	for _, e := range s.Enum {
+		def.Enum = append(def.Enum, e.Value())
+	}
+	for _, e := range s.Examples {
+		def.Examples = append(def.Examples, e.Value())
+	}
+	return def
+}
+
+func newConstraints(s *jsonschema.Schema) Constraints {
+	constraints := Constraints{}
+	set := func(key string, value any, ok bool) {
+		if ok {
+			constraints[key] = value
+		}
+	}
+	set("const", s.Const.Value(), s.Const.IsSet())
+	setPtr(constraints, "exclusiveMaximum", s.ExclusiveMaximum)
+	setPtr(constraints, "exclusiveMinimum", s.ExclusiveMinimum)
+	set("format", s.Format, s.Format != "")
+	setPtr(constraints, "maxItems", s.MaxItems)
+	setPtr(constraints, "maxLength", s.MaxLength)
+	setPtr(constraints, "maxProperties", s.MaxProperties)
+	setPtr(constraints, "maximum", s.Maximum)
+	setPtr(constraints, "minItems", s.MinItems)
+	setPtr(constraints, "minLength", s.MinLength)
+	setPtr(constraints, "minProperties", s.MinProperties)
+	setPtr(constraints, "minimum", s.Minimum)
+	setPtr(constraints, "multipleOf", s.MultipleOf)
+	set("pattern", s.Pattern, s.Pattern != "")
+	set("uniqueItems", s.UniqueItems, s.UniqueItems)
+	if len(constraints) == 0 {
+		return nil
+	}
+	return constraints
+}
+
+// setPtr sets key to the value of ptr, if the keyword is present
+// (so that zero values, i.e. `minimum: 0`, are kept).
+func setPtr[T any](constraints Constraints, key string, ptr *T) {
+	if ptr != nil {
+		constraints[key] = *ptr
+	}
+}
+
+// newLinks returns links to the entities referenced by the type of s
+// (including those of array items).
+func newLinks(s *jsonschema.Schema) []Link {
+	links := []Link{}
+	seen := map[string]bool{}
+	var walk func(s *jsonschema.Schema, depth int)
+	walk = func(s *jsonschema.Schema, depth int) {
+		// Guard against recursive item schemas.
+		if depth > 32 {
+			return
+		}
+		for _, ti := range s.TypeInfo() {
+			if ti.Schema == nil {
+				continue
+			}
+			switch ti.Type {
+			case jsonschema.TypeArray:
+				walk(ti.Schema, depth+1)
+			case jsonschema.TypeObject:
+				if ti.Schema.Entity().EntityName() == "" {
+					continue
+				}
+				link := newLink(ti.Schema)
+				if !seen[link.URI] {
+					seen[link.URI] = true
+					links = append(links, link)
+				}
+			}
+		}
+	}
+	walk(s, 0)
+	if len(links) == 0 {
+		return nil
+	}
+	return links
+}
+
+func newLink(s *jsonschema.Schema) Link {
+	entity := s.Entity()
+	return Link{
+		Name: entity.EntityName(),
+		URI:  entity.EntityURI(),
+		Href: s.EntityLink(),
+	}
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func newSource(s *jsonschema.Schema) Source {
+	return Source{
+		URI:     s.SourceURI(),
+		Pointer: s.Pointer,
+	}
+}
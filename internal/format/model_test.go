@@ -0,0 +1,60 @@
+package format
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sebdah/goldie/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/twelvelabs/termite/render"
+
+	"github.com/twelvelabs/schemadoc/internal/jsonschema"
+)
+
+func TestNewModel(t *testing.T) {
+	require := require.New(t)
+
+	doc, err := os.ReadFile(filepath.Join("testdata", "widget.schema.json"))
+	require.NoError(err)
+	// Load from a fixed URI so that the source locations are stable.
+	uri := "https://example.com/widget.schema.json"
+	loader := jsonschema.NewMemoryLoader(nil)
+	loader.Store(uri, doc)
+	context := jsonschema.NewContext(jsonschema.WithLoader(loader, "https"))
+
+	root, err := context.Get(uri)
+	require.NoError(err)
+	tpl, err := render.Compile("{{ .EntityName | underscore }}.json")
+	require.NoError(err)
+	root.GenPathTpl = *tpl
+
+	model := NewModel(root)
+	require.Equal(ModelVersion, model.Version)
+	require.Equal("Widget", model.Name)
+	require.Nil(model.Links)
+	require.Len(model.Properties, 3)
+
+	parts := model.Properties[1]
+	require.Equal("parts", parts.Name)
+	require.Equal("Part[]", parts.Type)
+	require.Equal("/properties/parts", parts.Source.Pointer)
+	require.Equal([]Link{
+		{
+			Name: "Part",
+			URI:  "https://example.com/widget.schema.json#/definitions/part",
+			Href: "part.json#part",
+		},
+	}, parts.Links)
+
+	part := NewModel(root.Definitions["part"])
+	require.Equal("part.json", part.Path)
+	require.Equal(Constraints{"minimum": float64(1)}, part.Properties[0].Constraints)
+	// Zero values are kept.
+	require.Equal(Constraints{"minimum": float64(-10), "maximum": float64(0)}, part.Properties[1].Constraints)
+
+	actual, err := model.JSON()
+	require.NoError(err)
+	g := goldie.New(t)
+	g.Assert(t, "model", []byte(actual))
+}
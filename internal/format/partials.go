@@ -0,0 +1,118 @@
+package format
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/twelvelabs/schemadoc/internal/jsonschema"
+)
+
+// Override parses the template files in dir that belong to the format
+// into tpl (its parsed entity template), overriding the templates they
+// define. Files belong to a format if they have the extension of its
+// entity template (i.e. `.md` for the markdown and html formats):
+//
+//   - A file named after the built-in entity template (i.e. `markdown.tpl.md`,
+//     as exported by `schemadoc templates export`) replaces it.
+//   - Files named after other built-in templates (i.e. `index.tpl.md`) are skipped.
+//   - A file named after a template defined by the built-in template, sans
+//     extension (i.e. `PropertiesTpl.md`), is a partial overriding it.
+//     Files named like partials (ending in `Tpl`) that match none are
+//     an error (usually a typo).
+//   - Any other file may `{{ define }}` templates (i.e. helpers used by
+//     partials, or overrides), and is skipped if it has content outside
+//     of them (i.e. a `README.md`).
+//
+// Partials may also `{{ define }}` their own helpers.
+// Templates that are not overridden fall back to those already in tpl.
+// Files of other formats (or that are not templates) are skipped.
+func (f *Format) Override(tpl *template.Template, dir string) error {
+	return override(tpl, f.Template, dir, true)
+}
+
+// OverrideIndex parses the file in dir named after the built-in index
+// template of the format (i.e. `index.tpl.md`), if any, into tpl
+// (its parsed index template), replacing it.
+func (f *Format) OverrideIndex(tpl *template.Template, dir string) error {
+	return override(tpl, f.IndexTemplate, dir, false)
+}
+
+// partialSuffix is the suffix of the names of the templates
+// defined by the built-in templates (i.e. `PropertiesTpl`).
+const partialSuffix = "Tpl"
+
+// override parses the files in dir overriding the built-in template
+// at builtin (parsed as tpl), and partials if enabled.
+func override(tpl *template.Template, builtin string, dir string, partials bool) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	ext := path.Ext(builtin)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || path.Ext(name) != ext {
+			// Not a template of this format.
+			continue
+		}
+		tplName := strings.TrimSuffix(name, ext)
+		switch {
+		case name == path.Base(builtin):
+			tplName = name
+		case isBuiltinTemplate(path.Join(path.Dir(builtin), name)) || !partials:
+			continue
+		case tpl.Lookup(tplName) == nil && strings.HasSuffix(tplName, partialSuffix):
+			return fmt.Errorf(
+				"%s: %s does not define %s (see `schemadoc templates list`)",
+				name, path.Base(builtin), tplName,
+			)
+		}
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		if tplName != name && tpl.Lookup(tplName) == nil {
+			// Only its `{{ define }}`s are used.
+			if ok, err := definesOnly(tpl, tplName, string(content)); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			} else if !ok {
+				continue
+			}
+		}
+		target := tpl
+		if tplName != tpl.Name() {
+			target = tpl.New(tplName)
+		}
+		if _, err := target.Parse(string(content)); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// definesOnly returns true if content (parsed as the template named name,
+// alongside those in tpl) has no content outside of `{{ define }}`s.
+func definesOnly(tpl *template.Template, name string, content string) (bool, error) {
+	probe, err := tpl.Clone()
+	if err != nil {
+		return false, err
+	}
+	parsed, err := probe.New(name).Parse(content)
+	if err != nil {
+		return false, err
+	}
+	return parse.IsEmptyTree(parsed.Root), nil
+}
+
+// isBuiltinTemplate returns true if name is a file in [jsonschema.Templates].
+func isBuiltinTemplate(name string) bool {
+	info, err := fs.Stat(jsonschema.Templates, name)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,122 @@
+package format
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeTemplates writes files (name to content) to a new dir.
+func writeTemplates(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	return dir
+}
+
+// executeTemplate executes the template named name in tpl with data.
+func executeTemplate(t *testing.T, tpl *template.Template, name string, data any) string {
+	t.Helper()
+	buf := bytes.Buffer{}
+	require.NoError(t, tpl.ExecuteTemplate(&buf, name, data))
+	return buf.String()
+}
+
+func TestFormat_Override(t *testing.T) {
+	require := require.New(t)
+	f, err := Lookup(Markdown)
+	require.NoError(err)
+
+	dir := writeTemplates(t, map[string]string{
+		// Defines the template named after the file.
+		"PropertiesTpl.md": `props`,
+		// Defines other templates (and is otherwise empty).
+		"more.md": "{{ define \"ExamplesTpl\" }}examples{{ end }}\n",
+		// Other built-in templates (of this or other formats) are skipped.
+		"index.tpl.md":             `{{ nope`,
+		"restructuredtext.tpl.rst": `{{ nope`,
+		"EnumTpl.rst":              `{{ nope`,
+		// Partials may define (and use) their own helpers.
+		"EnumTpl.md": `{{ define "Row" }}- {{ . }}{{ end }}{{ range . }}{{ template "Row" . }}{{ end }}`,
+		// Not templates (of this format), so ignored.
+		".hidden.md": `{{ nope`,
+		"README.md":  `Templates for the docs.`,
+		"README.txt": `{{ nope`,
+	})
+	require.NoError(os.Mkdir(filepath.Join(dir, "html"), 0700))
+
+	tpl, err := f.Parse(testFuncs())
+	require.NoError(err)
+	require.NoError(f.Override(tpl, dir))
+	require.Equal("props", executeTemplate(t, tpl, "PropertiesTpl", nil))
+	require.Equal("examples", executeTemplate(t, tpl, "ExamplesTpl", nil))
+	require.Equal("- a- b", executeTemplate(t, tpl, "EnumTpl", []string{"a", "b"}))
+	require.Nil(tpl.Lookup("README"))
+
+	// The entity template is replaced by the file with its built-in name.
+	require.NoError(os.WriteFile(filepath.Join(dir, "markdown.tpl.md"), []byte(`entity`), 0600))
+	tpl, err = f.Parse(testFuncs())
+	require.NoError(err)
+	require.NoError(f.Override(tpl, dir))
+	require.Equal("entity", executeTemplate(t, tpl, tpl.Name(), nil))
+}
+
+func TestFormat_Override_WhenInvalid(t *testing.T) {
+	f, err := Lookup(Markdown)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		err   string
+	}{
+		{
+			name:  "should reject partials named after unknown templates",
+			files: map[string]string{"TypeTpl.md": `type`},
+			err:   "TypeTpl.md: markdown.tpl.md does not define TypeTpl",
+		},
+		{
+			name:  "should report parse errors with the file name",
+			files: map[string]string{"bad.md": `{{ nope`},
+			err:   "bad.md:",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpl, err := f.Parse(testFuncs())
+			require.NoError(t, err)
+			require.ErrorContains(t, f.Override(tpl, writeTemplates(t, tt.files)), tt.err)
+		})
+	}
+
+	tpl, err := f.Parse(testFuncs())
+	require.NoError(t, err)
+	require.Error(t, f.Override(tpl, filepath.Join(t.TempDir(), "missing")))
+}
+
+func TestFormat_OverrideIndex(t *testing.T) {
+	require := require.New(t)
+	f, err := Lookup(Markdown)
+	require.NoError(err)
+
+	// Partials (and the entity template) are not applied to the index.
+	dir := writeTemplates(t, map[string]string{
+		"PropertiesTpl.md": `props`,
+		"markdown.tpl.md":  `{{ nope`,
+	})
+	tpl, err := f.ParseIndex(testFuncs())
+	require.NoError(err)
+	require.NoError(f.OverrideIndex(tpl, dir))
+	require.Nil(tpl.Lookup("PropertiesTpl"))
+
+	// The index template is replaced by the file with its built-in name.
+	require.NoError(os.WriteFile(filepath.Join(dir, "index.tpl.md"), []byte(`# {{ .Title }}`), 0600))
+	require.NoError(f.OverrideIndex(tpl, dir))
+	require.Equal("# Schemas", executeTemplate(t, tpl, tpl.Name(), &Index{Title: "Schemas"}))
+}
@@ -0,0 +1,314 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gobuffalo/flect"
+	"gopkg.in/yaml.v3"
+)
+
+// Preset adapts the generated pages for a static site generator:
+// each page is prefixed with YAML front matter, and navigation files
+// (i.e. a sidebar) are generated alongside them.
+type Preset struct {
+	// Name is the name used to select the preset (i.e. `--preset`).
+	Name string
+	// Formats are the names of the formats the site generator can render.
+	Formats []string
+	// FrontMatter returns the front matter fields for page.
+	FrontMatter func(page Page) *FrontMatter
+	// Nav returns the navigation files for pages (in page order).
+	// Title is the title of the root section, and base is the path
+	// of the output dir within the site's docs dir (see [Preset.NavBase]).
+	Nav func(pages []Page, title string, base string) ([]File, error)
+	// NavBase is true if the navigation refers to pages relative to the
+	// site's docs dir (rather than the output dir), so needs a base path
+	// when generating to a sub dir of it.
+	NavBase bool
+	// NoIndex is true if an index page would clash with the navigation
+	// (i.e. Hugo treats `index.md` as a leaf bundle, hiding the other pages).
+	NoIndex bool
+}
+
+// Page describes a generated page.
+type Page struct {
+	// Path is the slash separated path of the page, relative to the output dir.
+	Path string
+	// Title is the page title (i.e. the entity name).
+	Title string
+	// Description is a one sentence (plain text) summary of the page.
+	Description string
+	// Position is the (1-based) position of the page within the site.
+	Position int
+}
+
+// Slug returns the URL slug for the page.
+// Index pages have none, so that they are served at the URL of their dir.
+func (p Page) Slug() string {
+	if strings.TrimSuffix(path.Base(p.Path), path.Ext(p.Path)) == IndexName {
+		return ""
+	}
+	return flect.Dasherize(p.Title)
+}
+
+// FrontMatter is the YAML front matter of a page.
+// Unset fields are omitted.
+type FrontMatter struct {
+	Title           string `yaml:"title"`
+	Slug            string `yaml:"slug,omitempty"`
+	Description     string `yaml:"description,omitempty"`
+	SidebarPosition int    `yaml:"sidebar_position,omitempty"`
+	Weight          int    `yaml:"weight,omitempty"`
+}
+
+// File is a file generated by a preset.
+type File struct {
+	// Path is the slash separated path of the file, relative to the output dir.
+	Path    string
+	Content string
+}
+
+// Supports returns true if the site generator can render the format named name.
+func (p *Preset) Supports(name string) bool {
+	return slices.Contains(p.Formats, name)
+}
+
+// Render returns content prefixed with the front matter for page.
+func (p *Preset) Render(page Page, content string) (string, error) {
+	buf, err := marshalYAML(p.FrontMatter(page))
+	if err != nil {
+		return "", err
+	}
+	return "---\n" + buf + "---\n\n" + content, nil
+}
+
+var (
+	presets  = map[string]*Preset{}
+	presetMu sync.RWMutex
+)
+
+func init() {
+	RegisterPreset(&Preset{
+		Name:    Docusaurus,
+		Formats: []string{Markdown},
+		FrontMatter: func(page Page) *FrontMatter {
+			return &FrontMatter{
+				Title:           page.Title,
+				Slug:            page.Slug(),
+				Description:     page.Description,
+				SidebarPosition: page.Position,
+			}
+		},
+		Nav:     docusaurusNav,
+		NavBase: true,
+	})
+	RegisterPreset(&Preset{
+		Name: Hugo,
+		// Hugo renders AsciiDoc and reStructuredText using external helpers.
+		Formats: []string{Markdown, AsciiDoc, ReStructuredText},
+		FrontMatter: func(page Page) *FrontMatter {
+			return &FrontMatter{
+				Title:       page.Title,
+				Slug:        page.Slug(),
+				Description: page.Description,
+				Weight:      page.Position,
+			}
+		},
+		Nav:     hugoNav,
+		NoIndex: true,
+	})
+	RegisterPreset(&Preset{
+		Name:    MkDocs,
+		Formats: []string{Markdown},
+		FrontMatter: func(page Page) *FrontMatter {
+			// MkDocs orders pages by its nav, and derives URLs from paths.
+			return &FrontMatter{
+				Title:       page.Title,
+				Description: page.Description,
+			}
+		},
+		Nav:     mkdocsNav,
+		NavBase: true,
+	})
+}
+
+// Names of the built-in presets.
+const (
+	Docusaurus = "docusaurus"
+	Hugo       = "hugo"
+	MkDocs     = "mkdocs"
+)
+
+// Names of the files generated by the built-in presets.
+const (
+	DocusaurusSidebarName = "sidebars.js"
+	HugoIndexName         = "_index.md"
+	MkDocsNavName         = "mkdocs.nav.yml"
+)
+
+// RegisterPreset registers preset, replacing any preset with the same name.
+func RegisterPreset(preset *Preset) {
+	presetMu.Lock()
+	defer presetMu.Unlock()
+	presets[preset.Name] = preset
+}
+
+// LookupPreset returns the preset registered with name.
+func LookupPreset(name string) (*Preset, error) {
+	presetMu.RLock()
+	defer presetMu.RUnlock()
+	preset, ok := presets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q (must be one of %v)", name, presetNames())
+	}
+	return preset, nil
+}
+
+// PresetNames returns the (sorted) names of the registered presets.
+func PresetNames() []string {
+	presetMu.RLock()
+	defer presetMu.RUnlock()
+	return presetNames()
+}
+
+func presetNames() []string {
+	return slices.Sorted(maps.Keys(presets))
+}
+
+// navSection is a dir of pages (i.e. a sidebar category).
+type navSection struct {
+	Dir      string
+	Title    string
+	Position int
+	Pages    []Page
+	Sections []*navSection
+}
+
+// newNavTree groups pages by dir. Pages keep their order, and sections
+// are ordered by their first page.
+func newNavTree(pages []Page, title string) *navSection {
+	root := &navSection{Dir: ".", Title: title}
+	sections := map[string]*navSection{".": root}
+	var section func(dir string) *navSection
+	section = func(dir string) *navSection {
+		if s, ok := sections[dir]; ok {
+			return s
+		}
+		s := &navSection{
+			Dir:   dir,
+			Title: flect.Titleize(path.Base(dir)),
+		}
+		sections[dir] = s
+		parent := section(path.Dir(dir))
+		s.Position = len(parent.Pages) + len(parent.Sections) + 1
+		parent.Sections = append(parent.Sections, s)
+		return s
+	}
+	for _, page := range pages {
+		s := section(path.Dir(page.Path))
+		s.Pages = append(s.Pages, page)
+	}
+	return root
+}
+
+// docusaurusNav generates a `sidebars.js` defining a `schemas` sidebar.
+// Doc IDs are relative to the docs dir (i.e. prefixed with base).
+func docusaurusNav(pages []Page, _ string, base string) ([]File, error) {
+	var items func(s *navSection, indent string) string
+	items = func(s *navSection, indent string) string {
+		buf := strings.Builder{}
+		for _, page := range s.Pages {
+			id := path.Join(base, strings.TrimSuffix(page.Path, path.Ext(page.Path)))
+			fmt.Fprintf(&buf, "%s%s,\n", indent, EscapeJSON(id))
+		}
+		for _, sub := range s.Sections {
+			fmt.Fprintf(&buf, "%s{\n", indent)
+			fmt.Fprintf(&buf, "%s  type: \"category\",\n", indent)
+			fmt.Fprintf(&buf, "%s  label: %s,\n", indent, EscapeJSON(sub.Title))
+			fmt.Fprintf(&buf, "%s  items: [\n", indent)
+			buf.WriteString(items(sub, indent+"    "))
+			fmt.Fprintf(&buf, "%s  ],\n", indent)
+			fmt.Fprintf(&buf, "%s},\n", indent)
+		}
+		return buf.String()
+	}
+	content := "// Generated by schemadoc. Do not edit.\n" +
+		"module.exports = {\n" +
+		"  schemas: [\n" +
+		items(newNavTree(pages, ""), "    ") +
+		"  ],\n" +
+		"};\n"
+	return []File{{Path: DocusaurusSidebarName, Content: content}}, nil
+}
+
+// hugoNav generates an `_index.md` for every section (i.e. dir).
+func hugoNav(pages []Page, title string, _ string) ([]File, error) {
+	files := []File{}
+	var walk func(s *navSection) error
+	walk = func(s *navSection) error {
+		buf, err := marshalYAML(&FrontMatter{
+			Title:  s.Title,
+			Weight: s.Position,
+		})
+		if err != nil {
+			return err
+		}
+		files = append(files, File{
+			Path:    path.Join(s.Dir, HugoIndexName),
+			Content: "---\n" + buf + "---\n",
+		})
+		for _, sub := range s.Sections {
+			if err := walk(sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(newNavTree(pages, title)); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// mkdocsNav generates a nav fragment to include in `mkdocs.yml`.
+// Paths are relative to the docs dir (i.e. prefixed with base).
+func mkdocsNav(pages []Page, title string, base string) ([]File, error) {
+	var items func(s *navSection) []any
+	items = func(s *navSection) []any {
+		result := []any{}
+		for _, page := range s.Pages {
+			result = append(result, map[string]string{page.Title: path.Join(base, page.Path)})
+		}
+		for _, sub := range s.Sections {
+			result = append(result, map[string]any{sub.Title: items(sub)})
+		}
+		return result
+	}
+	buf, err := marshalYAML([]any{
+		map[string]any{title: items(newNavTree(pages, title))},
+	})
+	if err != nil {
+		return nil, err
+	}
+	content := "# Generated by schemadoc. Add to the `nav` of `mkdocs.yml`.\n" + buf
+	return []File{{Path: MkDocsNavName, Content: content}}, nil
+}
+
+func marshalYAML(v any) (string, error) {
+	buf := bytes.Buffer{}
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,136 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testPages = []Page{
+	{Path: "widget.md", Title: "Widget", Description: "A widget.", Position: 1},
+	{Path: "v1/part.md", Title: "Part", Position: 2},
+	{Path: "v1/gear_box.md", Title: "GearBox", Position: 3},
+}
+
+func TestLookupPreset(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal([]string{Docusaurus, Hugo, MkDocs}, PresetNames())
+
+	_, err := LookupPreset("nope")
+	require.ErrorContains(err, `unknown preset "nope"`)
+}
+
+func TestPreset_Render(t *testing.T) {
+	tests := []struct {
+		preset string
+		want   string
+	}{
+		{
+			preset: Docusaurus,
+			want:   "---\ntitle: Widget\nslug: widget\ndescription: A widget.\nsidebar_position: 1\n---\n\n# Widget\n",
+		},
+		{
+			preset: Hugo,
+			want:   "---\ntitle: Widget\nslug: widget\ndescription: A widget.\nweight: 1\n---\n\n# Widget\n",
+		},
+		{
+			preset: MkDocs,
+			want:   "---\ntitle: Widget\ndescription: A widget.\n---\n\n# Widget\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.preset, func(t *testing.T) {
+			preset, err := LookupPreset(tt.preset)
+			require.NoError(t, err)
+
+			rendered, err := preset.Render(testPages[0], "# Widget\n")
+			require.NoError(t, err)
+			require.Equal(t, tt.want, rendered)
+		})
+	}
+}
+
+func TestPage_Slug(t *testing.T) {
+	require.Equal(t, "gear-box", testPages[2].Slug())
+	require.Equal(t, "", Page{Path: "index.md", Title: "Schemas"}.Slug())
+}
+
+func TestDocusaurusNav(t *testing.T) {
+	files, err := docusaurusNav(testPages, "Schemas", "")
+	require.NoError(t, err)
+	require.Equal(t, []File{
+		{
+			Path: DocusaurusSidebarName,
+			Content: `// Generated by schemadoc. Do not edit.
+module.exports = {
+  schemas: [
+    "widget",
+    {
+      type: "category",
+      label: "V1",
+      items: [
+        "v1/part",
+        "v1/gear_box",
+      ],
+    },
+  ],
+};
+`,
+		},
+	}, files)
+}
+
+func TestDocusaurusNav_WhenBase(t *testing.T) {
+	files, err := docusaurusNav(testPages, "Schemas", "schemas")
+	require.NoError(t, err)
+	require.Contains(t, files[0].Content, `    "schemas/widget",`)
+	require.Contains(t, files[0].Content, `        "schemas/v1/part",`)
+}
+
+func TestHugoNav(t *testing.T) {
+	files, err := hugoNav(testPages, "Schemas", "")
+	require.NoError(t, err)
+	require.Equal(t, []File{
+		{Path: "_index.md", Content: "---\ntitle: Schemas\n---\n"},
+		{Path: "v1/_index.md", Content: "---\ntitle: V1\nweight: 2\n---\n"},
+	}, files)
+}
+
+func TestMkDocsNav(t *testing.T) {
+	files, err := mkdocsNav(testPages, "Schemas", "")
+	require.NoError(t, err)
+	require.Equal(t, []File{
+		{
+			Path: MkDocsNavName,
+			Content: "# Generated by schemadoc. Add to the `nav` of `mkdocs.yml`.\n" +
+				"- Schemas:\n" +
+				"    - Widget: widget.md\n" +
+				"    - V1:\n" +
+				"        - Part: v1/part.md\n" +
+				"        - GearBox: v1/gear_box.md\n",
+		},
+	}, files)
+}
+
+func TestMkDocsNav_WhenBase(t *testing.T) {
+	files, err := mkdocsNav(testPages, "Schemas", "reference/schemas")
+	require.NoError(t, err)
+	require.Contains(t, files[0].Content, "    - Widget: reference/schemas/widget.md\n")
+	require.Contains(t, files[0].Content, "        - Part: reference/schemas/v1/part.md\n")
+}
+
+func TestPreset_Supports(t *testing.T) {
+	require := require.New(t)
+
+	docusaurus, err := LookupPreset(Docusaurus)
+	require.NoError(err)
+	require.True(docusaurus.Supports(Markdown))
+	require.False(docusaurus.Supports(ReStructuredText))
+	require.False(docusaurus.Supports(HTML))
+
+	hugo, err := LookupPreset(Hugo)
+	require.NoError(err)
+	require.True(hugo.Supports(AsciiDoc))
+	require.False(hugo.Supports(JSON))
+}
@@ -0,0 +1,460 @@
+package jsonschema
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ArchiveSeparator separates the location of an archive
+// from the path of a file within it.
+const ArchiveSeparator = "!"
+
+// NewZipLoader returns an ArchiveLoader for zip files.
+// URIs look like `zip:///path/to/bundle.zip!/schemas/foo.json`.
+func NewZipLoader() *ArchiveLoader {
+	return NewArchiveLoader(openZip)
+}
+
+// NewTarLoader returns an ArchiveLoader for (optionally gzipped) tar files.
+// URIs look like `tar:///path/to/bundle.tar.gz!/schemas/foo.json`.
+func NewTarLoader() *ArchiveLoader {
+	return NewArchiveLoader(openTar)
+}
+
+// NewGitLoader returns an ArchiveLoader for revisions of a local git repo.
+// URIs look like `git:///path/to/repo@HEAD~5!/schemas/foo.json`.
+// Requires the `git` executable.
+func NewGitLoader() *ArchiveLoader {
+	return NewArchiveLoader(openGit)
+}
+
+// archiveLoaders are the constructors of the built-in archive loaders by scheme.
+// They are not registered by default, so that refs in (untrusted) schemas
+// can not read local archives or run git; see [Registry.RegisterArchive].
+var archiveLoaders = map[string]func() *ArchiveLoader{
+	"zip": NewZipLoader,
+	"tar": NewTarLoader,
+	"git": NewGitLoader,
+}
+
+// IsArchiveScheme returns true if scheme is that of a built-in archive loader
+// (i.e. `zip`, `tar` or `git`).
+func IsArchiveScheme(scheme string) bool {
+	_, ok := archiveLoaders[scheme]
+	return ok
+}
+
+// NewArchiveLoader returns an ArchiveLoader that uses open
+// to read the archive at a given location into an [fs.FS].
+func NewArchiveLoader(open func(location string) (fs.FS, error)) *ArchiveLoader {
+	return &ArchiveLoader{
+		archives: map[string]fs.FS{},
+		open:     open,
+	}
+}
+
+// ArchiveLoader loads files from within archives.
+// Each archive is only opened once (zip and tar files are read
+// into memory, while git revisions are read as files are opened).
+type ArchiveLoader struct {
+	// allowed are the archive locations that may be read
+	// (nil if any may be).
+	allowed  map[string]bool
+	archives map[string]fs.FS
+	mu       sync.Mutex
+	open     func(location string) (fs.FS, error)
+}
+
+// Allow restricts the loader to reading the archive at location
+// (and any others already allowed).
+func (l *ArchiveLoader) Allow(location string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.allowed == nil {
+		l.allowed = map[string]bool{}
+	}
+	l.allowed[location] = true
+}
+
+// Load opens the file within the archive referenced by uri.
+func (l *ArchiveLoader) Load(uri *url.URL) (io.ReadCloser, error) {
+	fsys, name, err := l.FS(uri)
+	if err != nil {
+		return nil, err
+	}
+	return fsys.Open(name)
+}
+
+// FS returns the filesystem for the archive referenced by uri,
+// along with the name of the referenced file within it.
+func (l *ArchiveLoader) FS(uri *url.URL) (fs.FS, string, error) {
+	location, name, err := splitArchivePath(uri.Path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.allowed != nil && !l.allowed[location] {
+		return nil, "", fmt.Errorf("%s: archive not allowed (only archives passed as inputs can be read)", location)
+	}
+	fsys, ok := l.archives[location]
+	if !ok {
+		fsys, err = l.open(location)
+		if err != nil {
+			return nil, "", err
+		}
+		l.archives[location] = fsys
+	}
+	return fsys, name, nil
+}
+
+// ArchiveURI returns the URI for the file name within the archive
+// at location. For git, location should be in the form `REPO@REV`.
+func ArchiveURI(scheme string, location string, name string) string {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	return scheme + "://" + filepath.ToSlash(location) + ArchiveSeparator + "/" + name
+}
+
+// ExpandArchiveLocation expands shorthand archive locations
+// into absolute archive URIs:
+//
+//   - `zip:bundle.zip!/schemas/` (relative to the working dir)
+//   - `tar:bundle.tar.gz!/schemas/` (relative to the working dir)
+//   - `git:REV:schemas/` (relative to the root of the current repo)
+//
+// Locations that are not shorthand are returned unchanged.
+func ExpandArchiveLocation(location string) (string, error) {
+	scheme, rest, ok := strings.Cut(location, ":")
+	if !ok || strings.HasPrefix(rest, "//") {
+		return location, nil
+	}
+
+	switch scheme {
+	case "zip", "tar":
+		archive, name, ok := strings.Cut(rest, ArchiveSeparator)
+		if !ok {
+			return "", fmt.Errorf("%s: missing %q separator", location, ArchiveSeparator)
+		}
+		archive, err := filepath.Abs(archive)
+		if err != nil {
+			return "", err
+		}
+		return ArchiveURI(scheme, archive, name), nil
+	case "git":
+		rev, name, ok := strings.Cut(rest, ":")
+		if !ok || rev == "" {
+			return "", fmt.Errorf("%s: expected git:REV:PATH", location)
+		}
+		root, err := gitRoot(".")
+		if err != nil {
+			return "", err
+		}
+		return ArchiveURI(scheme, root+"@"+rev, name), nil
+	default:
+		return location, nil
+	}
+}
+
+// splitArchivePath splits p into the location of the archive
+// and the name of the file within it.
+func splitArchivePath(p string) (string, string, error) {
+	location, name, ok := strings.Cut(p, ArchiveSeparator)
+	if !ok {
+		return "", "", fmt.Errorf("%s: missing %q separator", p, ArchiveSeparator)
+	}
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		name = "."
+	}
+	return filepath.FromSlash(location), name, nil
+}
+
+// openZip reads the zip file at location into memory.
+func openZip(location string) (fs.FS, error) {
+	buf, err := os.ReadFile(location)
+	if err != nil {
+		return nil, err
+	}
+	return zip.NewReader(bytes.NewReader(buf), int64(len(buf)))
+}
+
+// openTar reads the (optionally gzipped) tar file at location into memory.
+func openTar(location string) (fs.FS, error) {
+	f, err := os.Open(location)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readTar(f)
+}
+
+// openGit returns a filesystem reading the given revision of a git repo.
+// Location must be in the form `REPO@REV`. Files (and dirs) are only
+// read from the repo when opened, so that only the paths used are read.
+func openGit(location string) (fs.FS, error) {
+	idx := strings.LastIndex(location, "@")
+	if idx < 0 {
+		return nil, fmt.Errorf("%s: expected REPO@REV", location)
+	}
+	repo, rev := location[:idx], location[idx+1:]
+	if rev == "" || strings.HasPrefix(rev, "-") {
+		// i.e. would be read as an option.
+		return nil, fmt.Errorf("%s: invalid revision %q", location, rev)
+	}
+
+	// Resolved once, so every file is read from the same commit.
+	out, err := runGit(repo, "rev-parse", "--verify", "--end-of-options", rev+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("git rev-parse %s: %w", rev, err)
+	}
+	return &gitFS{repo: repo, commit: strings.TrimSpace(string(out))}, nil
+}
+
+// runGit runs git with args in repo and returns its output.
+func runGit(repo string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", repo}, args...)...) //nolint:gosec
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// gitRoot returns the root dir of the git repo containing dir.
+func gitRoot(dir string) (string, error) {
+	cmd := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// readTar reads all regular files in the (optionally gzipped)
+// tar stream into an in-memory filesystem.
+func readTar(r io.Reader) (fs.FS, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = br
+	}
+
+	fsys := newMemFS()
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		fsys.add(name, data, fs.FileMode(header.Mode).Perm(), header.ModTime) //nolint:gosec
+	}
+	return fsys, nil
+}
+
+// memFS is a read-only, in-memory filesystem of the regular files
+// in an archive. Dirs are implied by the paths of the files.
+type memFS struct {
+	entries  map[string]*memEntry
+	children map[string][]string
+}
+
+// newMemFS returns an empty memFS.
+func newMemFS() *memFS {
+	return &memFS{
+		entries:  map[string]*memEntry{".": {name: ".", mode: fs.ModeDir | 0o755}},
+		children: map[string][]string{},
+	}
+}
+
+// add adds the file at name (and its parent dirs).
+func (m *memFS) add(name string, data []byte, mode fs.FileMode, modTime time.Time) {
+	m.entries[name] = &memEntry{name: path.Base(name), size: int64(len(data)), mode: mode, modTime: modTime, data: data}
+	for child := name; child != "."; child = path.Dir(child) {
+		dir := path.Dir(child)
+		if !slices.Contains(m.children[dir], child) {
+			m.children[dir] = append(m.children[dir], child)
+		}
+		if _, ok := m.entries[dir]; !ok {
+			m.entries[dir] = &memEntry{name: path.Base(dir), mode: fs.ModeDir | 0o755}
+		}
+	}
+}
+
+// Open opens the named file (or dir).
+func (m *memFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	entry, ok := m.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if !entry.IsDir() {
+		return entry.open(), nil
+	}
+	entries := []fs.DirEntry{}
+	for _, child := range m.children[name] {
+		entries = append(entries, fs.FileInfoToDirEntry(m.entries[child]))
+	}
+	return newMemDir(entry, entries), nil
+}
+
+// gitFS is a read-only filesystem of a commit in a git repo.
+type gitFS struct {
+	repo   string
+	commit string
+}
+
+// Open opens the named file (or dir), reading it from the repo.
+func (g *gitFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	object := g.commit + ":"
+	if name != "." {
+		object += name
+	}
+	out, err := runGit(g.repo, "cat-file", "-t", object)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	switch strings.TrimSpace(string(out)) {
+	case "blob":
+		data, err := runGit(g.repo, "cat-file", "blob", object)
+		if err != nil {
+			return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+		}
+		entry := &memEntry{name: path.Base(name), size: int64(len(data)), mode: 0o644, data: data}
+		return entry.open(), nil
+	case "tree":
+		out, err := runGit(g.repo, "ls-tree", "-z", "-l", object)
+		if err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+		}
+		entries := []fs.DirEntry{}
+		for line := range strings.SplitSeq(strings.TrimSuffix(string(out), "\x00"), "\x00") {
+			// i.e. `<mode> <type> <object> <size>\t<name>`.
+			info, childName, ok := strings.Cut(line, "\t")
+			fields := strings.Fields(info)
+			if !ok || len(fields) != 4 {
+				continue
+			}
+			child := &memEntry{name: childName, mode: 0o644}
+			switch fields[1] {
+			case "blob":
+				child.size, _ = strconv.ParseInt(fields[3], 10, 64)
+			case "tree":
+				child.mode = fs.ModeDir | 0o755
+			default:
+				// i.e. submodules.
+				continue
+			}
+			entries = append(entries, fs.FileInfoToDirEntry(child))
+		}
+		return newMemDir(&memEntry{name: path.Base(name), mode: fs.ModeDir | 0o755}, entries), nil
+	default:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+}
+
+// memEntry is a file (or dir) in memory. It implements [fs.FileInfo].
+type memEntry struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+	data    []byte
+}
+
+func (e *memEntry) Name() string       { return e.name }
+func (e *memEntry) Size() int64        { return e.size }
+func (e *memEntry) Mode() fs.FileMode  { return e.mode }
+func (e *memEntry) ModTime() time.Time { return e.modTime }
+func (e *memEntry) IsDir() bool        { return e.mode.IsDir() }
+func (e *memEntry) Sys() any           { return nil }
+
+// open returns an open file reading the entry.
+func (e *memEntry) open() fs.File {
+	return &memFile{Reader: bytes.NewReader(e.data), entry: e}
+}
+
+// memFile is an open memEntry.
+type memFile struct {
+	*bytes.Reader
+	entry *memEntry
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.entry, nil }
+func (f *memFile) Close() error               { return nil }
+
+// memDir is an open dir, listing entries (sorted by name).
+type memDir struct {
+	entry   *memEntry
+	entries []fs.DirEntry
+	offset  int
+}
+
+// newMemDir returns an open dir listing entries.
+func newMemDir(entry *memEntry, entries []fs.DirEntry) *memDir {
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+	return &memDir{entry: entry, entries: entries}
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.entry, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.entry.name, Err: fs.ErrInvalid}
+}
+
+// ReadDir returns the next n entries (or all remaining entries if n <= 0).
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	entries := d.entries[d.offset:]
+	if n > 0 {
+		if len(entries) == 0 {
+			return nil, io.EOF
+		}
+		entries = entries[:min(n, len(entries))]
+	}
+	d.offset += len(entries)
+	return entries, nil
+}
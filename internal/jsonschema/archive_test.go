@@ -0,0 +1,230 @@
+package jsonschema
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+var archiveFixtures = map[string]string{
+	"schemas/root.schema.json":  `{"title": "Root", "properties": {"child": {"$ref": "child.schema.json"}}}`,
+	"schemas/child.schema.json": `{"title": "Child"}`,
+}
+
+func writeZipFixture(t *testing.T) string {
+	t.Helper()
+	location := filepath.Join(t.TempDir(), "bundle.zip")
+	f, err := os.Create(location)
+	require.NoError(t, err)
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for name, content := range archiveFixtures {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = io.WriteString(w, content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return location
+}
+
+func writeTarFixture(t *testing.T) string {
+	t.Helper()
+	location := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	f, err := os.Create(location)
+	require.NoError(t, err)
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Name:     "schemas/",
+		Typeflag: tar.TypeDir,
+		Mode:     0755,
+	}))
+	for name, content := range archiveFixtures {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Typeflag: tar.TypeReg,
+			Mode:     0644,
+			Size:     int64(len(content)),
+		}))
+		_, err = io.WriteString(tw, content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	return location
+}
+
+func writeGitFixture(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	git("init", "--quiet")
+	for name, content := range archiveFixtures {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	git("add", ".")
+	git("commit", "--quiet", "-m", "first")
+
+	// Change the root schema so that HEAD~1 is distinguishable from HEAD.
+	path := filepath.Join(dir, "schemas", "root.schema.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"title": "Changed"}`), 0o600))
+	git("commit", "--quiet", "-am", "second")
+
+	return dir
+}
+
+func TestArchiveLoaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		scheme   string
+		location func(t *testing.T) string
+	}{
+		{"zip", "zip", writeZipFixture},
+		{"tar", "tar", writeTarFixture},
+		{"git", "git", func(t *testing.T) string {
+			t.Helper()
+			return writeGitFixture(t) + "@HEAD~1"
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			location := tt.location(t)
+			uri := ArchiveURI(tt.scheme, location, "schemas/root.schema.json")
+			parsed, err := url.Parse(uri)
+			require.NoError(err)
+
+			// Archives can not be loaded until registered.
+			_, err = NewContext().Get(uri)
+			require.ErrorContains(err, "unknown scheme: "+tt.scheme)
+			registry := NewDefaultRegistry()
+			loader, err := registry.RegisterArchive(parsed)
+			require.NoError(err)
+			context := NewContext(WithRegistry(registry))
+
+			// Relative refs should resolve within the archive.
+			schema, err := context.Get(uri)
+			require.NoError(err)
+			require.Equal("Root", schema.Title)
+			require.Equal("Child", schema.Properties["child"].Title)
+
+			// Should expose the archive as a filesystem.
+			fsys, name, err := loader.FS(parsed)
+			require.NoError(err)
+			require.Equal("schemas/root.schema.json", name)
+			matches, err := fs.Glob(fsys, "schemas/*.schema.json")
+			require.NoError(err)
+			require.Equal([]string{
+				"schemas/child.schema.json",
+				"schemas/root.schema.json",
+			}, matches)
+
+			require.NoError(fstest.TestFS(fsys, "schemas/child.schema.json", "schemas/root.schema.json"))
+
+			// Unknown files should return an error.
+			_, err = context.Get(ArchiveURI(tt.scheme, location, "unknown.json"))
+			require.Error(err)
+
+			// As should other (unregistered) archives.
+			_, err = context.Get(ArchiveURI(tt.scheme, location+"-other", "schemas/root.schema.json"))
+			require.ErrorContains(err, "archive not allowed")
+		})
+	}
+}
+
+func TestArchiveLoader_WhenInvalid(t *testing.T) {
+	require := require.New(t)
+
+	_, err := NewZipLoader().Load(&url.URL{Scheme: "zip", Path: "/bundle.zip"})
+	require.ErrorContains(err, "missing \"!\" separator")
+
+	_, err = NewZipLoader().Load(&url.URL{Scheme: "zip", Path: "/unknown.zip!/foo.json"})
+	require.ErrorIs(err, fs.ErrNotExist)
+
+	_, err = NewTarLoader().Load(&url.URL{Scheme: "tar", Path: "/unknown.tar!/foo.json"})
+	require.ErrorIs(err, fs.ErrNotExist)
+
+	_, err = NewGitLoader().Load(&url.URL{Scheme: "git", Path: "/repo!/foo.json"})
+	require.ErrorContains(err, "expected REPO@REV")
+
+	// Revisions must not be read as git options.
+	output := filepath.Join(t.TempDir(), "pwned")
+	_, err = NewGitLoader().Load(&url.URL{Scheme: "git", Path: "/repo@--output=" + output + "!/foo.json"})
+	require.ErrorContains(err, "invalid revision")
+	require.NoFileExists(output)
+
+	_, err = NewDefaultRegistry().RegisterArchive(&url.URL{Scheme: "https", Path: "/foo!/bar.json"})
+	require.ErrorContains(err, "unknown archive scheme: https")
+}
+
+func TestArchiveURI(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal("zip:///tmp/b.zip!/schemas/a.json", ArchiveURI("zip", "/tmp/b.zip", "schemas/a.json"))
+	require.Equal("zip:///tmp/b.zip!/schemas/a.json", ArchiveURI("zip", "/tmp/b.zip", "/schemas/./a.json"))
+	require.Equal("git:///repo@HEAD~5!/", ArchiveURI("git", "/repo@HEAD~5", ""))
+}
+
+func TestExpandArchiveLocation(t *testing.T) {
+	require := require.New(t)
+
+	wd, err := os.Getwd()
+	require.NoError(err)
+
+	for _, location := range []string{
+		"schemas/foo.json",
+		"https://example.com/foo.json",
+		"zip:///tmp/b.zip!/schemas/",
+	} {
+		expanded, err := ExpandArchiveLocation(location)
+		require.NoError(err)
+		require.Equal(location, expanded)
+	}
+
+	expanded, err := ExpandArchiveLocation("zip:b.zip!/schemas/")
+	require.NoError(err)
+	require.Equal(ArchiveURI("zip", filepath.Join(wd, "b.zip"), "schemas"), expanded)
+
+	_, err = ExpandArchiveLocation("tar:b.tar")
+	require.ErrorContains(err, "missing \"!\" separator")
+
+	_, err = ExpandArchiveLocation("git:schemas/")
+	require.ErrorContains(err, "expected git:REV:PATH")
+
+	// Git paths are relative to the root of the current repo.
+	if root, err := gitRoot("."); err == nil {
+		expanded, err := ExpandArchiveLocation("git:HEAD~5:schemas/")
+		require.NoError(err)
+		require.Equal(ArchiveURI("git", root+"@HEAD~5", "schemas"), expanded)
+	}
+}
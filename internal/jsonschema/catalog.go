@@ -0,0 +1,179 @@
+package jsonschema
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// LoadCatalog loads the SchemaStore-style catalog located at location
+// using the [DefaultRegistry].
+func LoadCatalog(location string) (*Catalog, error) {
+	return DefaultRegistry.LoadCatalog(location)
+}
+
+// RegisterCatalog registers the mappings for every entry in catalog
+// in the [DefaultRegistry].
+func RegisterCatalog(catalog *Catalog) {
+	DefaultRegistry.RegisterCatalog(catalog)
+}
+
+// parseCatalog parses the catalog document read from reader.
+func parseCatalog(reader io.Reader, location string) (*Catalog, error) {
+	doc, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := &Catalog{}
+	if err := json.Unmarshal(doc, catalog); err != nil {
+		return nil, fmt.Errorf("parse catalog %s: %w", location, err)
+	}
+	catalog.Location = location
+	return catalog, nil
+}
+
+// Catalog is a list of schemas in the format used by SchemaStore.
+// See: https://www.schemastore.org/api/json/catalog.json
+type Catalog struct {
+	Schema   string          `json:"$schema,omitempty"`
+	Version  int             `json:"version,omitempty"`
+	Schemas  []*CatalogEntry `json:"schemas"`
+	Location string          `json:"-"`
+}
+
+// CatalogEntry is a single schema in a [Catalog].
+type CatalogEntry struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	FileMatch   []string          `json:"fileMatch,omitempty"`
+	URL         string            `json:"url"`
+	Versions    map[string]string `json:"versions,omitempty"`
+
+	// Path is a non-standard attribute pointing to a local copy
+	// of the schema at URL. Relative paths (and relative URLs)
+	// are resolved against the location of the catalog.
+	Path string `json:"path,omitempty"`
+
+	// ID is the `$id` of the schema at URL when URL is a local file
+	// (read when the catalog is loaded). Refs to ID load the local file.
+	ID string `json:"-"`
+}
+
+// Lookup returns the entry whose name or URL matches nameOrURL,
+// or nil if there is no such entry. Names are matched case-insensitively.
+func (c *Catalog) Lookup(nameOrURL string) *CatalogEntry {
+	for _, entry := range c.Schemas {
+		if entry.URL == nameOrURL || strings.EqualFold(entry.Name, nameOrURL) ||
+			(entry.ID != "" && entry.ID == nameOrURL) {
+			return entry
+		}
+	}
+	return nil
+}
+
+// Resolve returns the location that should be used to retrieve
+// the schema identified by nameOrURL. Entries with a canonical URL
+// (or a local file with an `$id`) resolve to that URL (so that
+// relative refs continue to resolve against it), otherwise to
+// their local location.
+func (c *Catalog) Resolve(nameOrURL string) (string, bool) {
+	entry := c.Lookup(nameOrURL)
+	if entry == nil {
+		return "", false
+	}
+	if isAbsURI(entry.URL) {
+		return entry.URL, true
+	}
+	if entry.ID != "" {
+		return entry.ID, true
+	}
+	return c.locate(entry.URL), true
+}
+
+// Mappings returns the URI mappings needed to load
+// every entry with a local copy from that copy.
+func (c *Catalog) Mappings() map[string]string {
+	mappings := map[string]string{}
+	for _, entry := range c.Schemas {
+		switch {
+		case entry.Path != "" && isAbsURI(entry.URL):
+			mappings[entry.URL] = c.locate(entry.Path)
+		case entry.ID != "" && !isAbsURI(entry.URL):
+			mappings[entry.ID] = c.locate(entry.URL)
+		}
+	}
+	return mappings
+}
+
+// readIDs sets the ID of the entries whose URL is a local file,
+// reading them with load. Unreadable files are skipped
+// (they fail when they are used instead).
+func (c *Catalog) readIDs(load func(location string) (io.ReadCloser, error)) {
+	for _, entry := range c.Schemas {
+		if isAbsURI(entry.URL) {
+			continue
+		}
+		location := c.locate(entry.URL)
+		if isAbsURI(location) {
+			// Only local catalogs (the entries of remote ones would need fetching).
+			continue
+		}
+		reader, err := load(location)
+		if err != nil {
+			continue
+		}
+		doc, err := io.ReadAll(reader)
+		_ = reader.Close()
+		if err != nil {
+			continue
+		}
+		ids := struct {
+			ID      string `json:"$id"`
+			DraftID string `json:"id"` // draft-04
+		}{}
+		if err := json.Unmarshal(doc, &ids); err != nil {
+			continue
+		}
+		id := strings.TrimSuffix(cmp.Or(ids.ID, ids.DraftID), "#")
+		if isAbsURI(id) {
+			entry.ID = id
+		}
+	}
+}
+
+// locate resolves location against the location of the catalog.
+func (c *Catalog) locate(location string) string {
+	if isAbsURI(location) || c.Location == "" {
+		return location
+	}
+	if isAbsURI(c.Location) {
+		base, err := url.Parse(c.Location)
+		if err != nil {
+			return location
+		}
+		ref, err := url.Parse(location)
+		if err != nil {
+			return location
+		}
+		return base.ResolveReference(ref).String()
+	}
+	if filepath.IsAbs(location) {
+		return location
+	}
+	return filepath.Join(filepath.Dir(c.Location), location)
+}
+
+// isAbsURI returns true if location has a URI scheme.
+// Single letter schemes are assumed to be Windows drive letters.
+func isAbsURI(location string) bool {
+	u, err := url.Parse(location)
+	if err != nil {
+		return false
+	}
+	return len(u.Scheme) > 1
+}
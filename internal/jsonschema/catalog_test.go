@@ -0,0 +1,111 @@
+package jsonschema
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCatalog(t *testing.T) {
+	require := require.New(t)
+
+	catalog, err := LoadCatalog(filepath.Join("testdata", "unknown.json"))
+	require.Error(err)
+	require.Nil(catalog)
+
+	catalog, err = LoadCatalog(filepath.Join("testdata", "invalid.json"))
+	require.ErrorContains(err, "parse catalog")
+	require.Nil(catalog)
+
+	path := filepath.Join("testdata", "catalog", "catalog.json")
+	catalog, err = LoadCatalog(path)
+	require.NoError(err)
+	require.Equal(path, catalog.Location)
+	require.Equal(1, catalog.Version)
+	require.Len(catalog.Schemas, 4)
+	require.Equal("GitHub Workflow", catalog.Schemas[0].Name)
+	require.Equal([]string{"**/.github/workflows/*.yml"}, catalog.Schemas[0].FileMatch)
+
+	// The `$id` of local entries is read (when they have one).
+	require.Equal("", catalog.Schemas[1].ID)
+	require.Equal("https://schemas.example.com/pinned.json", catalog.Schemas[2].ID)
+}
+
+func TestCatalog_Resolve(t *testing.T) {
+	require := require.New(t)
+
+	catalog, err := LoadCatalog(filepath.Join("testdata", "catalog", "catalog.json"))
+	require.NoError(err)
+
+	location, ok := catalog.Resolve("unknown")
+	require.False(ok)
+	require.Equal("", location)
+
+	// Entries w/ canonical URLs resolve to that URL...
+	location, ok = catalog.Resolve("github workflow")
+	require.True(ok)
+	require.Equal("https://json.schemastore.org/github-workflow.json", location)
+	location, ok = catalog.Resolve("https://json.schemastore.org/github-workflow.json")
+	require.True(ok)
+	require.Equal("https://json.schemastore.org/github-workflow.json", location)
+
+	// ... otherwise to the location relative to the catalog.
+	location, ok = catalog.Resolve("Local")
+	require.True(ok)
+	require.Equal(filepath.Join("testdata", "catalog", "local.json"), location)
+
+	// Local entries w/ an `$id` resolve to it (by name or `$id`).
+	location, ok = catalog.Resolve("pinned")
+	require.True(ok)
+	require.Equal("https://schemas.example.com/pinned.json", location)
+	location, ok = catalog.Resolve("https://schemas.example.com/pinned.json")
+	require.True(ok)
+	require.Equal("https://schemas.example.com/pinned.json", location)
+}
+
+func TestCatalog_Mappings(t *testing.T) {
+	require := require.New(t)
+
+	catalog, err := LoadCatalog(filepath.Join("testdata", "catalog", "catalog.json"))
+	require.NoError(err)
+	require.Equal(map[string]string{
+		"https://json.schemastore.org/github-workflow.json": filepath.Join(
+			"testdata", "catalog", "github-workflow.json",
+		),
+		"https://schemas.example.com/pinned.json": filepath.Join(
+			"testdata", "catalog", "pinned.json",
+		),
+	}, catalog.Mappings())
+
+	catalog.Location = "https://example.com/api/catalog.json"
+	require.Equal(map[string]string{
+		"https://json.schemastore.org/github-workflow.json": "https://example.com/api/github-workflow.json",
+		"https://schemas.example.com/pinned.json":           "https://example.com/api/pinned.json",
+	}, catalog.Mappings())
+}
+
+func TestRegisterCatalog(t *testing.T) {
+	require := require.New(t)
+
+	catalog, err := LoadCatalog(filepath.Join("testdata", "catalog", "catalog.json"))
+	require.NoError(err)
+
+	RegisterCatalog(catalog)
+	t.Cleanup(func() {
+		UnregisterMapping("https://json.schemastore.org/github-workflow.json")
+		UnregisterMapping("https://schemas.example.com/pinned.json")
+	})
+
+	// Refs to the canonical URL should resolve to the pinned local copy.
+	context := NewContext()
+	schema, err := context.Get(filepath.Join("testdata", "catalog", "local.json"))
+	require.NoError(err)
+	require.Equal("Workflow", schema.Properties["workflow"].Title)
+	require.Equal("The name of your workflow.", schema.Properties["workflow"].Properties["name"].Description)
+
+	// Refs to the `$id` of a local entry should resolve to that entry.
+	schema, err = context.Get(filepath.Join("testdata", "catalog", "consumer.json"))
+	require.NoError(err)
+	require.Equal("Pinned", schema.Properties["pinned"].Title)
+}
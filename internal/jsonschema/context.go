@@ -3,20 +3,93 @@ package jsonschema
 import (
 	"encoding/json"
 	"io"
+	"maps"
+	"net/http"
+	"net/url"
 	"path"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/xeipuuv/gojsonpointer"
 )
 
-func NewContext() *Context {
-	return &Context{
-		schemas: map[string]*Schema{},
+// ContextOption configures a [Context].
+type ContextOption func(c *Context)
+
+// WithRegistry sets the loader registry used by the context.
+// Defaults to the [DefaultRegistry].
+func WithRegistry(registry *Registry) ContextOption {
+	return func(c *Context) {
+		c.registry = registry
+		c.owned = false
+	}
+}
+
+// WithLoader registers loader for the given schemes on the context only.
+// The context's registry is copied first, so other contexts
+// (and the [DefaultRegistry]) are unaffected.
+func WithLoader(loader Loader, schemes ...string) ContextOption {
+	return func(c *Context) {
+		c.ownRegistry().RegisterLoader(loader, schemes...)
 	}
 }
 
+// WithMapping registers a URI mapping on the context only.
+// See [Registry.RegisterMapping] for details.
+func WithMapping(prefix string, target string) ContextOption {
+	return func(c *Context) {
+		c.ownRegistry().RegisterMapping(prefix, target)
+	}
+}
+
+// WithHTTPClient loads HTTP(S) URIs using client
+// (for example, one configured with credentials or a proxy).
+func WithHTTPClient(client *http.Client) ContextOption {
+	return WithLoader(NewCachedLoader(NewHTTPLoader(client)), "http", "https")
+}
+
+// WithCache caches every document loaded by the context in cache.
+// Contexts may share a cache, or use their own to stay isolated.
+func WithCache(cache *Cache) ContextOption {
+	return func(c *Context) {
+		c.cache = cache
+	}
+}
+
+// NewContext returns a new Context configured with opts.
+func NewContext(opts ...ContextOption) *Context {
+	c := &Context{
+		registry: DefaultRegistry,
+		schemas:  map[string]*Schema{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Context loads, resolves and stores schemas.
 type Context struct {
-	schemas map[string]*Schema
+	cache    *Cache
+	owned    bool
+	registry *Registry
+	schemas  map[string]*Schema
+}
+
+// Registry returns the loader registry used by the context.
+func (c *Context) Registry() *Registry {
+	return c.registry
+}
+
+// ownRegistry returns a registry that is safe to modify
+// without affecting any other context.
+func (c *Context) ownRegistry() *Registry {
+	if !c.owned {
+		c.registry = c.registry.Clone()
+		c.owned = true
+	}
+	return c.registry
 }
 
 func (c *Context) Get(ref string) (*Schema, error) {
@@ -25,10 +98,14 @@ func (c *Context) Get(ref string) (*Schema, error) {
 		return schema, nil
 	}
 
-	// Try looking up a sub-schema ref.
-	base, frag, _ := strings.Cut(ref, "#")
-	if schema := c.lookup(base); schema != nil {
-		subSchema, err := c.parseSubSchema(schema, frag)
+	// Sub-schema refs are parsed from their (possibly new) root schema.
+	base, frag, hasFrag := strings.Cut(ref, "#")
+	if hasFrag {
+		root, err := c.Get(base)
+		if err != nil {
+			return nil, err
+		}
+		subSchema, err := c.parseSubSchema(root, frag)
 		if err != nil {
 			return nil, err
 		}
@@ -56,7 +133,7 @@ func (c *Context) Get(ref string) (*Schema, error) {
 
 	// Note that we must store _before_ resolving, otherwise resolving
 	// in-schema refs will trigger an infinite loop.
-	return resolveSubSchemas(schema)
+	return resolveSubSchemas(schema, nil)
 }
 
 // lookup returns the stored Schema for ref,
@@ -75,7 +152,15 @@ func (c *Context) store(ref string, schema *Schema) {
 
 // load fetches the schema document located at ref.
 func (c *Context) load(ref string) ([]byte, error) {
-	reader, err := Load(ref)
+	var reader io.ReadCloser
+	var err error
+	if c.cache != nil {
+		reader, err = c.cache.Load(ref, func() (io.ReadCloser, error) {
+			return c.registry.Load(ref)
+		})
+	} else {
+		reader, err = c.registry.Load(ref)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -129,73 +214,88 @@ func (c *Context) parseSubSchema(schema *Schema, ref string) (*Schema, error) {
 	}
 	// Keep track of the ref base so we can use it in `.EntityName()`.
 	subSchema.Key = path.Base(ref)
+	subSchema.Pointer = ref
 
 	return subSchema, nil
 }
 
-// resolve recursively resolves all refs in the given schema.
-func resolveSubSchemas(schema *Schema) (*Schema, error) {
+// resolveSubSchemas recursively resolves all refs in the given schema.
+// Stack contains the URIs of the refs currently being resolved,
+// so that recursive schemas do not recurse forever.
+func resolveSubSchemas(schema *Schema, stack []string) (*Schema, error) {
 	if schema.Items != nil {
 		schema.Items.Parent = schema
-		tmp, err := resolveRef(schema.Items)
-		if err != nil {
-			return nil, err
-		}
-		*schema.Items = *tmp
-		tmp, err = resolveSubSchemas(schema.Items)
-		if err != nil {
+		pointer := pointerJoin(schema.Pointer, "items")
+		if err := resolveSubSchema(schema.Items, "", pointer, stack); err != nil {
 			return nil, err
 		}
-		*schema.Items = *tmp
 	}
 
-	for key, subSchema := range schema.Definitions {
-		subSchema.Key = key
+	for _, key := range slices.Sorted(maps.Keys(schema.Definitions)) {
+		subSchema := schema.Definitions[key]
 		subSchema.Parent = schema
-		tmp, err := resolveRef(subSchema)
-		if err != nil {
-			return nil, err
-		}
-		*subSchema = *tmp
-		tmp, err = resolveSubSchemas(subSchema)
-		if err != nil {
+		pointer := pointerJoin(schema.Pointer, "definitions", key)
+		if err := resolveSubSchema(subSchema, key, pointer, stack); err != nil {
 			return nil, err
 		}
-		*subSchema = *tmp
 	}
 
-	for key, subSchema := range schema.Properties {
-		subSchema.Key = key
+	for _, key := range slices.Sorted(maps.Keys(schema.Properties)) {
+		subSchema := schema.Properties[key]
 		subSchema.Parent = schema
-		tmp, err := resolveRef(subSchema)
-		if err != nil {
-			return nil, err
-		}
-		*subSchema = *tmp
-		tmp, err = resolveSubSchemas(subSchema)
-		if err != nil {
+		pointer := pointerJoin(schema.Pointer, "properties", key)
+		if err := resolveSubSchema(subSchema, key, pointer, stack); err != nil {
 			return nil, err
 		}
-		*subSchema = *tmp
 	}
 
-	for _, subSchema := range schema.OneOf {
+	for idx, subSchema := range schema.OneOf {
 		subSchema.Parent = schema
-		tmp, err := resolveRef(subSchema)
-		if err != nil {
-			return nil, err
-		}
-		*subSchema = *tmp
-		tmp, err = resolveSubSchemas(subSchema)
-		if err != nil {
+		pointer := pointerJoin(schema.Pointer, "oneOf", strconv.Itoa(idx))
+		if err := resolveSubSchema(subSchema, "", pointer, stack); err != nil {
 			return nil, err
 		}
-		*subSchema = *tmp
 	}
 
 	return schema, nil
 }
 
+// resolveSubSchema resolves the ref for (and then the children of)
+// schema in place. Key and pointer locate schema within its parent.
+func resolveSubSchema(schema *Schema, key string, pointer string, stack []string) error {
+	if key != "" {
+		schema.Key = key
+	}
+	schema.Pointer = pointer
+
+	resolved, err := resolveRef(schema)
+	if err != nil {
+		return err
+	}
+	*schema = *resolved
+
+	if schema.Origin != nil {
+		// Resolved from a ref, so the schema (and its children)
+		// now live at the ref target within the origin document.
+		if key != "" {
+			schema.Key = key
+		}
+		schema.Pointer = schema.Origin.Pointer
+
+		uri := schema.Origin.EntityURI()
+		if slices.Contains(stack, uri) {
+			// Recursive ref. The origin document has its own
+			// (resolved) copy, so no need to go any deeper.
+			return nil
+		}
+		stack = append(stack, uri)
+	}
+
+	_, err = resolveSubSchemas(schema, stack)
+	return err
+}
+
+// resolveRef resolves the ref of schema (if any).
 func resolveRef(schema *Schema) (*Schema, error) {
 	// Already resolved.
 	if schema.Resolved {
@@ -208,12 +308,23 @@ func resolveRef(schema *Schema) (*Schema, error) {
 		return schema, nil
 	}
 
+	root := schema.DocumentRoot()
+	frame := RefFrame{
+		Source:  root.RetrievalURI,
+		Pointer: pointerJoin(schema.Pointer, "$ref"),
+		Ref:     schema.Ref,
+	}
+
 	// Get the fully qualified ref URI.
-	refURI := schema.Root().RefURI(schema.Ref)
+	refURI, err := url.Parse(schema.Ref)
+	if err != nil {
+		return nil, newResolveError(frame, err)
+	}
+	refURI = root.BaseURI().ResolveReference(refURI)
 	// Load the schema for that ref.
-	loaded, err := schema.Root().Context.Get(refURI.String())
+	loaded, err := root.Context.Get(refURI.String())
 	if err != nil {
-		return nil, err
+		return nil, newResolveError(frame, err)
 	}
 	// Clone the loaded schema so we don't mutate the context.
 	resolved, err := loaded.Clone()
@@ -221,8 +332,9 @@ func resolveRef(schema *Schema) (*Schema, error) {
 		return nil, err
 	}
 
-	// Merge in the origin schema's properties and return.
+	// Merge in the referencing schema's properties and return.
 	resolved.Merge(schema)
+	resolved.Origin = loaded
 	resolved.Resolved = true
 	return resolved, nil
 }
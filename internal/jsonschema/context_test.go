@@ -1,10 +1,16 @@
 package jsonschema
 
 import (
+	"io"
+	"net/http"
+	"net/url"
 	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/require"
+	"github.com/twelvelabs/termite/api"
 )
 
 func TestContext_Get(t *testing.T) {
@@ -51,6 +57,110 @@ func TestContext_Get(t *testing.T) {
 	require.Same(veggie, veggie2)
 }
 
+func TestContext_WithRegistry(t *testing.T) {
+	require := require.New(t)
+
+	fsys := fstest.MapFS{
+		"root.json": &fstest.MapFile{Data: []byte(`{
+			"title": "Root",
+			"properties": {
+				"child": {"$ref": "child.json"}
+			}
+		}`)},
+		"child.json": &fstest.MapFile{Data: []byte(`{"title": "Child"}`)},
+	}
+	registry := NewRegistry()
+	registry.RegisterFS(fsys, "mem")
+
+	context := NewContext(WithRegistry(registry))
+	require.Same(registry, context.Registry())
+
+	schema, err := context.Get("mem:///root.json")
+	require.NoError(err)
+	require.Equal("Root", schema.Title)
+	require.Equal("Child", schema.Properties["child"].Title)
+
+	// The default context should not be able to see the in-memory files.
+	_, err = NewContext().Get("mem:///root.json")
+	require.ErrorContains(err, "unknown scheme: mem")
+}
+
+func TestContext_WithLoader(t *testing.T) {
+	require := require.New(t)
+
+	loader := &mockLoader{
+		LoadFunc: func(uri *url.URL) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(`{"title": "Mock"}`)), nil
+		},
+	}
+	context := NewContext(
+		WithLoader(loader, "mock"),
+		WithMapping("https://example.com/", "mock:///"),
+	)
+	require.NotSame(DefaultRegistry, context.Registry())
+	require.Nil(RegisteredLoader("mock"))
+	require.Equal("", RegisteredMapping("https://example.com/"))
+
+	schema, err := context.Get("https://example.com/foo.json")
+	require.NoError(err)
+	require.Equal("Mock", schema.Title)
+	require.Equal("https://example.com/foo.json", schema.RetrievalURI)
+
+	// Options should never modify a registry passed in by the caller.
+	registry := NewRegistry()
+	context = NewContext(WithRegistry(registry), WithLoader(loader, "mock"))
+	require.NotSame(registry, context.Registry())
+	require.Nil(registry.RegisteredLoader("mock"))
+}
+
+func TestContext_WithHTTPClient(t *testing.T) {
+	require := require.New(t)
+
+	transport := api.NewStubbedTransport()
+	transport.RegisterStub(
+		api.MatchGet("/foo.schema.json"),
+		api.StringResponse(`{"title": "Foo"}`),
+	)
+	defer transport.VerifyStubs(t)
+	client := &http.Client{Transport: transport}
+
+	context := NewContext(WithHTTPClient(client))
+	schema, err := context.Get("https://example.com/foo.schema.json")
+	require.NoError(err)
+	require.Equal("Foo", schema.Title)
+	require.NotSame(
+		RegisteredLoader("https"),
+		context.Registry().RegisteredLoader("https"),
+	)
+}
+
+func TestContext_WithCache(t *testing.T) {
+	require := require.New(t)
+
+	loader := &mockLoader{
+		LoadFunc: func(uri *url.URL) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(`{"title": "Mock"}`)), nil
+		},
+	}
+	registry := NewRegistry()
+	registry.RegisterLoader(loader, "mock")
+
+	// Contexts sharing a cache should only load once...
+	cache := NewCache()
+	for range 2 {
+		context := NewContext(WithRegistry(registry), WithCache(cache))
+		_, err := context.Get("mock:///foo.json")
+		require.NoError(err)
+	}
+	require.Equal(1, loader.LoadCalls)
+
+	// ... until the cache is cleared.
+	cache.Clear()
+	_, err := NewContext(WithRegistry(registry), WithCache(cache)).Get("mock:///foo.json")
+	require.NoError(err)
+	require.Equal(2, loader.LoadCalls)
+}
+
 func TestContext_parseSubSchema(t *testing.T) {
 	require := require.New(t)
 
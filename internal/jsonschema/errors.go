@@ -0,0 +1,82 @@
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RefFrame identifies a single `$ref` within a schema document.
+type RefFrame struct {
+	// Source is the URI of the document containing the `$ref`.
+	Source string
+	// Pointer is the JSON pointer to the `$ref` within Source.
+	Pointer string
+	// Ref is the value of the `$ref`.
+	Ref string
+}
+
+// String returns the frame formatted as `source#pointer`.
+func (f RefFrame) String() string {
+	return f.Source + "#" + f.Pointer
+}
+
+// ResolveError is returned when a `$ref` can not be resolved.
+type ResolveError struct {
+	// Chain contains every `$ref` followed to reach the one that failed,
+	// innermost (i.e. the failing ref) first.
+	Chain []RefFrame
+	// Err is the underlying cause.
+	Err error
+}
+
+// Frame returns the frame of the `$ref` that failed.
+func (e *ResolveError) Frame() RefFrame {
+	return e.Chain[0]
+}
+
+// Error returns a description of the failing ref, followed by
+// one line per ref in the chain.
+func (e *ResolveError) Error() string {
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "unable to resolve $ref %q: %v", e.Frame().Ref, e.Err)
+	for idx, frame := range e.Chain {
+		if idx == 0 {
+			fmt.Fprintf(&sb, "\n    at  %s", frame)
+		} else {
+			fmt.Fprintf(&sb, "\n    via %s", frame)
+		}
+	}
+	return sb.String()
+}
+
+// Unwrap returns the underlying cause.
+func (e *ResolveError) Unwrap() error {
+	return e.Err
+}
+
+// newResolveError returns a ResolveError for the ref described by frame.
+// If err is itself a ResolveError (from resolving a ref in the referenced
+// document), frame is appended to its chain instead.
+func newResolveError(frame RefFrame, err error) error {
+	var resolveErr *ResolveError
+	if errors.As(err, &resolveErr) {
+		resolveErr.Chain = append(resolveErr.Chain, frame)
+		return resolveErr
+	}
+	return &ResolveError{
+		Chain: []RefFrame{frame},
+		Err:   err,
+	}
+}
+
+// pointerJoin appends token to the JSON pointer ptr,
+// escaping it as needed (see RFC 6901).
+func pointerJoin(ptr string, tokens ...string) string {
+	for _, token := range tokens {
+		token = strings.ReplaceAll(token, "~", "~0")
+		token = strings.ReplaceAll(token, "/", "~1")
+		ptr += "/" + token
+	}
+	return ptr
+}
@@ -0,0 +1,79 @@
+package jsonschema
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveError(t *testing.T) {
+	require := require.New(t)
+
+	chain, err := filepath.Abs(filepath.Join("testdata", "ref_chain.schema.json"))
+	require.NoError(err)
+	broken, err := filepath.Abs(filepath.Join("testdata", "ref_broken.schema.json"))
+	require.NoError(err)
+
+	schema, err := NewContext().Get(chain)
+	require.Nil(schema)
+
+	var resolveErr *ResolveError
+	require.ErrorAs(err, &resolveErr)
+	require.ErrorIs(err, fs.ErrNotExist)
+	require.Equal([]RefFrame{
+		{
+			Source:  broken,
+			Pointer: "/properties/items/items/$ref",
+			Ref:     "missing.schema.json",
+		},
+		{
+			Source:  chain,
+			Pointer: "/properties/broken/$ref",
+			Ref:     "ref_broken.schema.json",
+		},
+	}, resolveErr.Chain)
+	require.Equal(resolveErr.Chain[0], resolveErr.Frame())
+
+	require.Equal(
+		`unable to resolve $ref "missing.schema.json": `+resolveErr.Err.Error()+"\n"+
+			"    at  "+broken+"#/properties/items/items/$ref\n"+
+			"    via "+chain+"#/properties/broken/$ref",
+		err.Error(),
+	)
+}
+
+func TestResolveError_WhenInvalidPointer(t *testing.T) {
+	require := require.New(t)
+
+	schema := &Schema{
+		RetrievalURI: "https://example.com/schema.json",
+		Ref:          "#/definitions/unknown",
+		Pointer:      "/properties/foo",
+		Document:     map[string]any{},
+		Context:      NewContext(),
+	}
+	schema.Context.store("https://example.com/schema.json", schema)
+
+	_, err := resolveRef(schema)
+	var resolveErr *ResolveError
+	require.ErrorAs(err, &resolveErr)
+	require.Equal(RefFrame{
+		Source:  "https://example.com/schema.json",
+		Pointer: "/properties/foo/$ref",
+		Ref:     "#/definitions/unknown",
+	}, resolveErr.Frame())
+	require.NotErrorIs(err, fs.ErrNotExist)
+	require.NotNil(errors.Unwrap(err))
+}
+
+func TestPointerJoin(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal("", pointerJoin(""))
+	require.Equal("/properties/foo", pointerJoin("", "properties", "foo"))
+	require.Equal("/definitions/a~1b~0c", pointerJoin("", "definitions", "a/b~c"))
+	require.Equal("/items/oneOf/0", pointerJoin("/items", "oneOf", "0"))
+}
@@ -0,0 +1,88 @@
+package jsonschema
+
+import (
+	"maps"
+	"path"
+	"slices"
+)
+
+// NewIndex returns an Index of the entities in roots and of every
+// entity (in any document) they reference.
+func NewIndex(roots ...*Schema) *Index {
+	idx := &Index{
+		entities: map[string]*Schema{},
+		visited:  map[*Schema]bool{},
+	}
+	for _, root := range roots {
+		idx.add(root)
+		for _, key := range slices.Sorted(maps.Keys(root.Definitions)) {
+			idx.add(root.Definitions[key].Entity())
+		}
+	}
+	for _, root := range roots {
+		idx.walk(root)
+	}
+	return idx
+}
+
+// Index is a set of entities (root schemas and their definitions),
+// keyed by [Schema.EntityURI]. Each entity is indexed once,
+// regardless of how many schemas reference it.
+type Index struct {
+	entities map[string]*Schema
+	order    []*Schema
+	visited  map[*Schema]bool
+}
+
+// Entities returns the indexed entities in the order they were found.
+func (idx *Index) Entities() []*Schema {
+	return slices.Clone(idx.order)
+}
+
+// Lookup returns the entity identified by uri, or nil if not indexed.
+func (idx *Index) Lookup(uri string) *Schema {
+	return idx.entities[uri]
+}
+
+// add indexes schema unless an entity with the same URI already is.
+func (idx *Index) add(schema *Schema) {
+	uri := schema.EntityURI()
+	if _, ok := idx.entities[uri]; ok {
+		return
+	}
+	idx.entities[uri] = schema
+	idx.order = append(idx.order, schema)
+}
+
+// walk indexes the entities referenced by schema and its children.
+func (idx *Index) walk(schema *Schema) {
+	if schema == nil || idx.visited[schema] {
+		return
+	}
+	idx.visited[schema] = true
+
+	if schema.Origin != nil {
+		entity := schema.Entity()
+		if isEntityPointer(entity.Pointer) {
+			idx.add(entity)
+		}
+		idx.walk(entity)
+	}
+
+	idx.walk(schema.Items)
+	for _, key := range slices.Sorted(maps.Keys(schema.Definitions)) {
+		idx.walk(schema.Definitions[key])
+	}
+	for _, key := range slices.Sorted(maps.Keys(schema.Properties)) {
+		idx.walk(schema.Properties[key])
+	}
+	for _, subSchema := range schema.OneOf {
+		idx.walk(subSchema)
+	}
+}
+
+// isEntityPointer returns true if pointer locates a root schema
+// or one of its definitions.
+func isEntityPointer(pointer string) bool {
+	return pointer == "" || path.Dir(pointer) == "/definitions"
+}
@@ -0,0 +1,66 @@
+package jsonschema
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIndex(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := filepath.Abs(filepath.Join("testdata", "shared"))
+	require.NoError(err)
+
+	context := NewContext()
+	a, err := context.Get(filepath.Join(dir, "a.schema.json"))
+	require.NoError(err)
+	b, err := context.Get(filepath.Join(dir, "b.schema.json"))
+	require.NoError(err)
+
+	index := NewIndex(a, b)
+
+	names := []string{}
+	for _, entity := range index.Entities() {
+		names = append(names, entity.EntityName())
+	}
+	require.Equal([]string{"A", "Local", "B", "Address", "Country"}, names)
+
+	// Both inputs reference the same (canonical) address entity.
+	address := a.Properties["home"].Entity()
+	require.Same(address, b.Properties["office"].Entity())
+	require.Same(address, a.Definitions["local"].Properties["work"].Entity())
+	require.Same(address, index.Lookup(address.EntityURI()))
+	require.Equal("Address", address.EntityName())
+	require.Equal("/definitions/address", address.Pointer)
+
+	// The referencing schema keeps its own key and description.
+	require.Equal("home", a.Properties["home"].Key)
+	require.Equal("Home address.", a.Properties["home"].Description)
+
+	require.Nil(index.Lookup("unknown.json#/definitions/foo"))
+}
+
+func TestNewIndex_Recursive(t *testing.T) {
+	require := require.New(t)
+
+	path, err := filepath.Abs(filepath.Join("testdata", "shared", "tree.schema.json"))
+	require.NoError(err)
+
+	context := NewContext()
+	tree, err := context.Get(path)
+	require.NoError(err)
+
+	index := NewIndex(tree)
+
+	names := []string{}
+	for _, entity := range index.Entities() {
+		names = append(names, entity.EntityName())
+	}
+	require.Equal([]string{"Tree", "Node"}, names)
+
+	node := tree.Definitions["node"]
+	require.Same(node, tree.Properties["root"].Entity())
+	require.Same(node, node.Properties["children"].Items.Entity())
+}
@@ -3,71 +3,94 @@ package jsonschema
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
-func init() {
-	// Register the default loaders.
-	// Package consumers are free to unregister or replace
-	// if they need something different.
-	RegisterLoader(
-		NewCachedLoader(NewFileLoader()),
-		"", // missing schemes are assumed to be `file`.
-		"file",
-	)
-	RegisterLoader(
-		NewCachedLoader(NewHTTPLoader(http.DefaultClient)),
-		"http",
-		"https",
-	)
-}
-
-var (
-	loaders   = map[string]Loader{}
-	loadersMu = sync.RWMutex{}
-)
-
-// Load loads the content from location using the appropriate Loader.
+// Load loads the content from location using the [DefaultRegistry].
 func Load(location string) (io.ReadCloser, error) {
-	u, err := url.Parse(location)
-	if err != nil {
-		return nil, fmt.Errorf("parse location: %w", err)
-	}
-
-	loader := RegisteredLoader(u.Scheme)
-	if loader == nil {
-		return nil, fmt.Errorf("unknown scheme: %s", u.Scheme)
-	}
-	return loader.Load(u)
+	return DefaultRegistry.Load(location)
 }
 
-// RegisterLoader registers a loader for the given schemes.
+// RegisterLoader registers a loader in the [DefaultRegistry].
 func RegisterLoader(loader Loader, schemes ...string) {
-	loadersMu.Lock()
-	defer loadersMu.Unlock()
-	for _, scheme := range schemes {
-		loaders[scheme] = loader
-	}
+	DefaultRegistry.RegisterLoader(loader, schemes...)
 }
 
-// RegisteredLoader returns the loader registered for a scheme.
+// RegisteredLoader returns the loader registered for a scheme
+// in the [DefaultRegistry].
 func RegisteredLoader(scheme string) Loader {
-	loadersMu.Lock()
-	defer loadersMu.Unlock()
-	return loaders[scheme]
+	return DefaultRegistry.RegisteredLoader(scheme)
 }
 
-// UnregisterLoader unregisters the loader for the given scheme.
+// UnregisterLoader unregisters the loader for the given scheme
+// from the [DefaultRegistry].
 func UnregisterLoader(scheme string) {
-	loadersMu.Lock()
-	defer loadersMu.Unlock()
-	delete(loaders, scheme)
+	DefaultRegistry.UnregisterLoader(scheme)
+}
+
+// RegisterMapping registers a URI mapping in the [DefaultRegistry].
+// See [Registry.RegisterMapping] for details.
+func RegisterMapping(prefix string, target string) {
+	DefaultRegistry.RegisterMapping(prefix, target)
+}
+
+// RegisteredMapping returns the target registered for prefix
+// in the [DefaultRegistry].
+func RegisteredMapping(prefix string) string {
+	return DefaultRegistry.RegisteredMapping(prefix)
+}
+
+// UnregisterMapping unregisters the mapping for prefix
+// from the [DefaultRegistry].
+func UnregisterMapping(prefix string) {
+	DefaultRegistry.UnregisterMapping(prefix)
+}
+
+// MapURI rewrites location using the mappings in the [DefaultRegistry].
+func MapURI(location string) string {
+	return DefaultRegistry.MapURI(location)
+}
+
+func mapURI(mappings map[string]string, location string) string {
+	matched := ""
+	for prefix := range mappings {
+		if len(prefix) > len(matched) && matchesPrefix(location, prefix) {
+			matched = prefix
+		}
+	}
+	if matched == "" {
+		return location
+	}
+
+	target := filepath.ToSlash(mappings[matched])
+	if strings.HasSuffix(matched, "/") && !strings.HasSuffix(target, "/") {
+		target += "/"
+	}
+	return target + strings.TrimPrefix(location, matched)
+}
+
+func matchesPrefix(location string, prefix string) bool {
+	if !strings.HasPrefix(location, prefix) {
+		return false
+	}
+	if strings.HasSuffix(prefix, "/") {
+		return true
+	}
+	rest := strings.TrimPrefix(location, prefix)
+	return rest == "" || strings.HasPrefix(rest, "#") || strings.HasPrefix(rest, "?")
 }
 
 /****************************************
@@ -98,40 +121,237 @@ func (l *FileLoader) Load(uri *url.URL) (io.ReadCloser, error) {
 	return os.Open(filepath.FromSlash(uri.Path))
 }
 
+/****************************************
+* FSLoader
+****************************************/
+
+// NewFSLoader returns a new FSLoader for fsys.
+func NewFSLoader(fsys fs.FS) *FSLoader {
+	return &FSLoader{
+		fsys: fsys,
+	}
+}
+
+// FSLoader loads files from an [fs.FS] (for example, an [embed.FS]).
+// The URI host and path are joined and treated as a path relative
+// to the root of the filesystem, so `embed:///schemas/foo.json` and
+// `embed://schemas/foo.json` both open `schemas/foo.json`.
+type FSLoader struct {
+	fsys fs.FS
+}
+
+// Load opens the given URI.
+func (l *FSLoader) Load(uri *url.URL) (io.ReadCloser, error) {
+	name := uri.Path
+	if uri.Opaque != "" {
+		name = uri.Opaque
+	}
+	name = path.Join(uri.Host, strings.TrimPrefix(name, "/"))
+	return l.fsys.Open(name)
+}
+
+/****************************************
+* MemoryLoader
+****************************************/
+
+// NewMemoryLoader returns a new MemoryLoader.
+// URIs that have not been stored are loaded by fallback (if not nil).
+func NewMemoryLoader(fallback Loader) *MemoryLoader {
+	return &MemoryLoader{
+		docs:     map[string][]byte{},
+		fallback: fallback,
+	}
+}
+
+// MemoryLoader loads documents stored in-memory
+// (for example, a schema read from stdin).
+type MemoryLoader struct {
+	docs     map[string][]byte
+	fallback Loader
+	mu       sync.RWMutex
+}
+
+// Store stores doc as the content for uri.
+func (l *MemoryLoader) Store(uri string, doc []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.docs[uri] = doc
+}
+
+// Load returns the stored content for the given URI.
+func (l *MemoryLoader) Load(uri *url.URL) (io.ReadCloser, error) {
+	l.mu.RLock()
+	doc, ok := l.docs[uri.String()]
+	l.mu.RUnlock()
+
+	if ok {
+		return io.NopCloser(bytes.NewReader(doc)), nil
+	}
+	if l.fallback != nil {
+		return l.fallback.Load(uri)
+	}
+	return nil, fmt.Errorf("open %s: %w", uri, fs.ErrNotExist)
+}
+
 /****************************************
 * HTTPLoader
 ****************************************/
 
+// HTTPLoaderOption configures an [HTTPLoader].
+type HTTPLoaderOption func(l *HTTPLoader)
+
+// WithHostHeaders adds headers to every request sent to host.
+// Host may include a port, in which case it must match exactly.
+func WithHostHeaders(host string, headers http.Header) HTTPLoaderOption {
+	return func(l *HTTPLoader) {
+		if l.headers[host] == nil {
+			l.headers[host] = http.Header{}
+		}
+		for key, values := range headers {
+			for _, value := range values {
+				l.headers[host].Add(key, value)
+			}
+		}
+	}
+}
+
+// WithRetries retries requests that fail with a 5xx or 429 status code
+// (or a transport error) up to retries times. The delay starts at backoff
+// and doubles after every attempt, unless the server sends a
+// `Retry-After` header.
+func WithRetries(retries int, backoff time.Duration) HTTPLoaderOption {
+	return func(l *HTTPLoader) {
+		l.retries = retries
+		l.backoff = backoff
+	}
+}
+
 // NewHTTPLoader returns a new HTTPLoader.
-func NewHTTPLoader(client *http.Client) *HTTPLoader {
-	return &HTTPLoader{
-		client: client,
+func NewHTTPLoader(client *http.Client, opts ...HTTPLoaderOption) *HTTPLoader {
+	l := &HTTPLoader{
+		client:  client,
+		headers: map[string]http.Header{},
+		sleep:   time.Sleep,
 	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
 }
 
 // HTTPLoader loads files via [net/http].
 type HTTPLoader struct {
-	client *http.Client
+	backoff time.Duration
+	client  *http.Client
+	headers map[string]http.Header
+	retries int
+	sleep   func(time.Duration)
 }
 
 // Load requests the given URI via HTTP GET.
 func (l *HTTPLoader) Load(uri *url.URL) (io.ReadCloser, error) {
+	delay := l.backoff
+	for attempt := 0; ; attempt++ {
+		body, retryAfter, err := l.get(uri)
+		if err == nil || retryAfter < 0 || attempt >= l.retries {
+			return body, err
+		}
+		if retryAfter > 0 {
+			delay = retryAfter
+		}
+		l.sleep(delay)
+		delay *= 2
+	}
+}
+
+// get performs a single GET request for uri.
+// When the request fails, retryAfter is >= 0 if it may be retried
+// (and > 0 if the server asked for a specific delay).
+func (l *HTTPLoader) get(uri *url.URL) (io.ReadCloser, time.Duration, error) {
 	url := uri.String()
 
 	// No way to trigger an error given these params.
 	request, _ := http.NewRequestWithContext(
 		context.Background(), http.MethodGet, url, nil,
 	)
+	for _, key := range []string{uri.Hostname(), uri.Host} {
+		for name, values := range l.headers[key] {
+			request.Header[name] = values
+		}
+	}
+
 	response, err := l.client.Do(request)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	if response.StatusCode != http.StatusOK {
 		_ = response.Body.Close()
-		return nil, fmt.Errorf("%s returned status code %d", url, response.StatusCode)
+		err := fmt.Errorf("%s returned status code %d", url, response.StatusCode)
+		if response.StatusCode != http.StatusTooManyRequests && response.StatusCode < 500 {
+			return nil, -1, err
+		}
+		return nil, parseRetryAfter(response.Header.Get("Retry-After")), err
 	}
 
-	return response.Body, nil
+	return response.Body, 0, nil
+}
+
+// parseRetryAfter returns the delay from a `Retry-After` header value
+// in seconds, or 0 if missing or not in that format.
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// HTTPClientConfig configures the client returned by [NewHTTPClient].
+type HTTPClientConfig struct {
+	// CAFile is the path to a PEM encoded bundle of additional
+	// certificate authorities to trust.
+	CAFile string
+	// Proxy is the URL of the proxy to use.
+	// Defaults to the standard `HTTPS_PROXY` and `HTTP_PROXY` env vars.
+	Proxy string
+	// Timeout is the time limit for each request (zero means no limit).
+	Timeout time.Duration
+}
+
+// NewHTTPClient returns a new [http.Client] configured with config.
+func NewHTTPClient(config HTTPClientConfig) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if config.Proxy != "" {
+		proxy, err := url.Parse(config.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("parse proxy: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("read CA file: no certificates found in %s", config.CAFile)
+		}
+		transport.TLSClientConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			RootCAs:    pool,
+		}
+	}
+
+	return &http.Client{
+		Timeout:   config.Timeout,
+		Transport: transport,
+	}, nil
 }
 
 /****************************************
@@ -142,36 +362,92 @@ func (l *HTTPLoader) Load(uri *url.URL) (io.ReadCloser, error) {
 // caches results in-memory.
 func NewCachedLoader(loader Loader) *CachedLoader {
 	return &CachedLoader{
-		loader:  loader,
-		results: map[string]cachedLoadResult{},
+		loader: loader,
+		cache:  NewCache(),
 	}
 }
 
 // CachedLoader caches the results of another Loader in-memory.
 type CachedLoader struct {
-	loader  Loader
-	results map[string]cachedLoadResult
+	loader Loader
+	cache  *Cache
 }
 
 // Load delegates to the wrapped loader and caches the result.
 func (l *CachedLoader) Load(uri *url.URL) (io.ReadCloser, error) {
-	key := uri.String()
+	return l.cache.Load(uri.String(), func() (io.ReadCloser, error) {
+		return l.loader.Load(uri)
+	})
+}
 
-	result, ok := l.results[key]
+// NewCache returns a new, empty Cache.
+func NewCache() *Cache {
+	return &Cache{
+		entries: map[string]*cacheEntry{},
+	}
+}
+
+// Cache stores the content (or error) of loaded documents by key.
+// It is safe for concurrent use: different keys are loaded concurrently,
+// and concurrent loads of the same key share a single call to load.
+type Cache struct {
+	entries map[string]*cacheEntry
+	mu      sync.Mutex
+}
+
+// cacheEntry is the result of loading a key.
+// The result is set before done is closed.
+type cacheEntry struct {
+	done   chan struct{}
+	result cachedLoadResult
+}
+
+// Load returns the cached result for key,
+// calling load to populate the cache if needed.
+func (c *Cache) Load(key string, load func() (io.ReadCloser, error)) (io.ReadCloser, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
 	if !ok {
-		result = l.newResultFor(uri)
-		l.results[key] = result
+		entry = &cacheEntry{done: make(chan struct{})}
+		c.entries[key] = entry
 	}
+	c.mu.Unlock()
 
+	if ok {
+		<-entry.done
+	} else {
+		func() {
+			defer close(entry.done)
+			// Replaced once load returns (i.e. unless it panics).
+			entry.result = cachedLoadResult{err: fmt.Errorf("load %s: did not complete", key)}
+			entry.result = newCachedLoadResult(load)
+		}()
+	}
+
+	result := entry.result
 	if result.buf == nil {
 		return nil, result.err
 	}
 	return io.NopCloser(bytes.NewReader(result.buf)), result.err
 }
 
-// newResultFor loads the URI and returns a new cachedLoadResult.
-func (l *CachedLoader) newResultFor(uri *url.URL) cachedLoadResult {
-	reader, err := l.loader.Load(uri)
+// Delete removes the cached result for key.
+func (c *Cache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// Clear removes all cached results.
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+// newCachedLoadResult calls load and returns a new cachedLoadResult.
+func newCachedLoadResult(load func() (io.ReadCloser, error)) cachedLoadResult {
+	reader, err := load()
 	if err != nil {
 		return cachedLoadResult{buf: nil, err: err}
 	}
@@ -3,12 +3,15 @@ package jsonschema
 import (
 	"errors"
 	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/twelvelabs/termite/api"
@@ -63,6 +66,64 @@ func TestLoaderRegistration(t *testing.T) {
 	require.Nil(RegisteredLoader("bar"))
 }
 
+func TestLoad_WithMapping(t *testing.T) {
+	require := require.New(t)
+
+	RegisterMapping("https://example.com/schemas/", "testdata")
+	t.Cleanup(func() {
+		UnregisterMapping("https://example.com/schemas/")
+	})
+
+	// Should load the mapped file rather than hitting the network.
+	reader, err := Load("https://example.com/schemas/basic.schema.json#/properties")
+	require.NoError(err)
+	actual, err := io.ReadAll(reader)
+	defer reader.Close()
+	require.NoError(err)
+
+	expected, err := os.ReadFile(filepath.Join("testdata", "basic.schema.json")) //nolint:gosec
+	require.NoError(err)
+	require.Equal(expected, actual)
+}
+
+func TestMappingRegistration(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal("", RegisteredMapping("https://example.com/"))
+
+	RegisterMapping("https://example.com/", "schemas")
+	require.Equal("schemas", RegisteredMapping("https://example.com/"))
+
+	UnregisterMapping("https://example.com/")
+	require.Equal("", RegisteredMapping("https://example.com/"))
+}
+
+func TestMapURI(t *testing.T) {
+	mappings := map[string]string{
+		"https://example.com/":                "vendor/example",
+		"https://example.com/common/":         "schemas/common/",
+		"https://example.com/foo.json":        "local/foo.json",
+		"https://mirror.example.com/schemas/": "https://mirror.example.org/",
+	}
+	tests := []struct {
+		location string
+		want     string
+	}{
+		{"https://other.com/foo.json", "https://other.com/foo.json"},
+		{"https://example.com/bar.json", "vendor/example/bar.json"},
+		{"https://example.com/common/v1/address.json", "schemas/common/v1/address.json"},
+		{"https://example.com/foo.json", "local/foo.json"},
+		{"https://example.com/foo.json#/definitions/a", "local/foo.json#/definitions/a"},
+		{"https://example.com/foo.jsonc", "vendor/example/foo.jsonc"},
+		{"https://mirror.example.com/schemas/a.json", "https://mirror.example.org/a.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			require.Equal(t, tt.want, mapURI(mappings, tt.location))
+		})
+	}
+}
+
 func TestFileLoader(t *testing.T) {
 	require := require.New(t)
 
@@ -85,6 +146,64 @@ func TestFileLoader(t *testing.T) {
 	require.Equal(expected, actual)
 }
 
+func TestFSLoader(t *testing.T) {
+	require := require.New(t)
+
+	loader := NewFSLoader(os.DirFS("testdata"))
+
+	uri, err := url.Parse("embed:///basic.schema.json")
+	require.NoError(err)
+	reader, err := loader.Load(uri)
+	require.NoError(err)
+
+	expected, err := os.ReadFile(filepath.Join("testdata", "basic.schema.json")) //nolint:gosec
+	require.NoError(err)
+
+	actual, err := io.ReadAll(reader)
+	defer reader.Close()
+	require.NoError(err)
+	require.Equal(expected, actual)
+
+	uri, err = url.Parse("embed:unknown.json")
+	require.NoError(err)
+	reader, err = loader.Load(uri)
+	require.ErrorIs(err, fs.ErrNotExist)
+	require.Nil(reader)
+}
+
+func TestMemoryLoader(t *testing.T) {
+	require := require.New(t)
+
+	loader := NewMemoryLoader(nil)
+	loader.Store("/tmp/stdin.json", []byte(`{"title": "Stdin"}`))
+
+	uri, err := url.Parse("/tmp/stdin.json")
+	require.NoError(err)
+	reader, err := loader.Load(uri)
+	require.NoError(err)
+	actual, err := io.ReadAll(reader)
+	defer reader.Close()
+	require.NoError(err)
+	require.Equal(`{"title": "Stdin"}`, string(actual))
+
+	uri, err = url.Parse(filepath.Join("testdata", "basic.schema.json"))
+	require.NoError(err)
+	reader, err = loader.Load(uri)
+	require.ErrorIs(err, fs.ErrNotExist)
+	require.Nil(reader)
+
+	// Everything else is delegated to the fallback loader.
+	loader = NewMemoryLoader(NewFileLoader())
+	reader, err = loader.Load(uri)
+	require.NoError(err)
+	defer reader.Close()
+	expected, err := os.ReadFile(filepath.Join("testdata", "basic.schema.json")) //nolint:gosec
+	require.NoError(err)
+	actual, err = io.ReadAll(reader)
+	require.NoError(err)
+	require.Equal(expected, actual)
+}
+
 func TestHTTPLoader(t *testing.T) {
 	require := require.New(t)
 
@@ -155,6 +274,169 @@ func TestHTTPLoader_WhenHTTPError(t *testing.T) {
 	require.Nil(reader)
 }
 
+func TestHTTPLoader_WithHostHeaders(t *testing.T) {
+	require := require.New(t)
+
+	transport := api.NewStubbedTransport()
+	transport.RegisterStub(
+		api.MatchGet("/private.json"),
+		api.StringResponse(`{}`),
+	)
+	transport.RegisterStub(
+		api.MatchGet("/public.json"),
+		api.StringResponse(`{}`),
+	)
+	defer transport.VerifyStubs(t)
+	client := &http.Client{Transport: transport}
+
+	loader := NewHTTPLoader(client,
+		WithHostHeaders("registry.example.com", http.Header{
+			"Authorization": []string{"Bearer secret"},
+		}),
+		WithHostHeaders("registry.example.com:8443", http.Header{
+			"X-Api-Key": []string{"key"},
+		}),
+	)
+
+	for _, location := range []string{
+		"https://registry.example.com:8443/private.json",
+		"https://example.com/public.json",
+	} {
+		uri, err := url.Parse(location)
+		require.NoError(err)
+		reader, err := loader.Load(uri)
+		require.NoError(err)
+		_ = reader.Close()
+	}
+
+	require.Len(transport.Requests, 2)
+	require.Equal("Bearer secret", transport.Requests[0].Header.Get("Authorization"))
+	require.Equal("key", transport.Requests[0].Header.Get("X-Api-Key"))
+	require.Equal("", transport.Requests[1].Header.Get("Authorization"))
+	require.Equal("", transport.Requests[1].Header.Get("X-Api-Key"))
+}
+
+func TestHTTPLoader_WithRetries(t *testing.T) {
+	require := require.New(t)
+
+	uri, err := url.Parse("https://example.com/foo.schema.json")
+	require.NoError(err)
+
+	transport := api.NewStubbedTransport()
+	transport.RegisterStub(
+		api.MatchGet("/foo.schema.json"),
+		api.WithStatus(503, api.StringResponse(``)),
+	)
+	transport.RegisterStub(
+		api.MatchGet("/foo.schema.json"),
+		api.WithStatus(429, api.WithHeader("Retry-After", "7", api.StringResponse(``))),
+	)
+	transport.RegisterStub(
+		api.MatchGet("/foo.schema.json"),
+		api.ErrorResponse(errors.New("connection reset")),
+	)
+	transport.RegisterStub(
+		api.MatchGet("/foo.schema.json"),
+		api.StringResponse(`{}`),
+	)
+	defer transport.VerifyStubs(t)
+	client := &http.Client{Transport: transport}
+
+	loader := NewHTTPLoader(client, WithRetries(3, time.Second))
+	delays := []time.Duration{}
+	loader.sleep = func(d time.Duration) {
+		delays = append(delays, d)
+	}
+
+	reader, err := loader.Load(uri)
+	require.NoError(err)
+	actual, err := io.ReadAll(reader)
+	defer reader.Close()
+	require.NoError(err)
+	require.Equal([]byte(`{}`), actual)
+	require.Equal([]time.Duration{
+		1 * time.Second,
+		7 * time.Second, // from Retry-After
+		14 * time.Second,
+	}, delays)
+}
+
+func TestHTTPLoader_WithRetries_WhenExhausted(t *testing.T) {
+	require := require.New(t)
+
+	uri, err := url.Parse("https://example.com/foo.schema.json")
+	require.NoError(err)
+
+	transport := api.NewStubbedTransport()
+	for range 2 {
+		transport.RegisterStub(
+			api.MatchGet("/foo.schema.json"),
+			api.WithStatus(500, api.StringResponse(``)),
+		)
+	}
+	defer transport.VerifyStubs(t)
+	client := &http.Client{Transport: transport}
+
+	loader := NewHTTPLoader(client, WithRetries(1, time.Second))
+	loader.sleep = func(time.Duration) {}
+
+	reader, err := loader.Load(uri)
+	require.ErrorContains(err, "returned status code 500")
+	require.Nil(reader)
+}
+
+func TestHTTPLoader_WithRetries_WhenNotRetryable(t *testing.T) {
+	require := require.New(t)
+
+	uri, err := url.Parse("https://example.com/foo.schema.json")
+	require.NoError(err)
+
+	transport := api.NewStubbedTransport()
+	transport.RegisterStub(
+		api.MatchGet("/foo.schema.json"),
+		api.WithStatus(401, api.StringResponse(``)),
+	)
+	defer transport.VerifyStubs(t)
+	client := &http.Client{Transport: transport}
+
+	loader := NewHTTPLoader(client, WithRetries(3, time.Second))
+	loader.sleep = func(time.Duration) {
+		require.Fail("should not retry")
+	}
+
+	reader, err := loader.Load(uri)
+	require.ErrorContains(err, "returned status code 401")
+	require.Nil(reader)
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	require := require.New(t)
+
+	client, err := NewHTTPClient(HTTPClientConfig{})
+	require.NoError(err)
+	require.Equal(time.Duration(0), client.Timeout)
+
+	client, err = NewHTTPClient(HTTPClientConfig{
+		Proxy:   "http://proxy.example.com:3128",
+		Timeout: 5 * time.Second,
+	})
+	require.NoError(err)
+	require.Equal(5*time.Second, client.Timeout)
+	request, _ := http.NewRequest(http.MethodGet, "https://example.com", nil) //nolint:noctx
+	proxy, err := client.Transport.(*http.Transport).Proxy(request)
+	require.NoError(err)
+	require.Equal("http://proxy.example.com:3128", proxy.String())
+
+	_, err = NewHTTPClient(HTTPClientConfig{Proxy: "http://proxy\n"})
+	require.ErrorContains(err, "parse proxy")
+
+	_, err = NewHTTPClient(HTTPClientConfig{CAFile: filepath.Join("testdata", "unknown.pem")})
+	require.ErrorContains(err, "read CA file")
+
+	_, err = NewHTTPClient(HTTPClientConfig{CAFile: filepath.Join("testdata", "basic.schema.json")})
+	require.ErrorContains(err, "no certificates found")
+}
+
 func TestCachedLoader(t *testing.T) {
 	require := require.New(t)
 
@@ -255,6 +537,36 @@ func TestCachedLoader_WhenReadError(t *testing.T) {
 	require.Equal(1, mReadCloser.ReadCalls)
 }
 
+func TestCache(t *testing.T) {
+	require := require.New(t)
+
+	calls := 0
+	load := func() (io.ReadCloser, error) {
+		calls++
+		return io.NopCloser(strings.NewReader("{}")), nil
+	}
+
+	cache := NewCache()
+	for range 2 {
+		reader, err := cache.Load("foo", load)
+		require.NoError(err)
+		actual, err := io.ReadAll(reader)
+		require.NoError(err)
+		require.Equal([]byte(`{}`), actual)
+	}
+	require.Equal(1, calls)
+
+	cache.Delete("foo")
+	_, err := cache.Load("foo", load)
+	require.NoError(err)
+	require.Equal(2, calls)
+
+	cache.Clear()
+	_, err = cache.Load("foo", load)
+	require.NoError(err)
+	require.Equal(3, calls)
+}
+
 type mockLoader struct {
 	LoadCalls int
 	LoadFunc  func(uri *url.URL) (io.ReadCloser, error)
@@ -277,3 +589,54 @@ func (r *mockReadCloser) Read(p []byte) (int, error) {
 func (r *mockReadCloser) Close() error {
 	return nil
 }
+
+func TestCache_Concurrent(t *testing.T) {
+	require := require.New(t)
+
+	cache := NewCache()
+	load := func(content string) func() (io.ReadCloser, error) {
+		return func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(content)), nil
+		}
+	}
+
+	// A slow load must not block loads of other keys.
+	started := make(chan struct{})
+	release := make(chan struct{})
+	slowDone := make(chan struct{})
+	calls := atomic.Int32{}
+	var slowErr error
+	go func() {
+		defer close(slowDone)
+		_, slowErr = cache.Load("slow", func() (io.ReadCloser, error) {
+			calls.Add(1)
+			close(started)
+			<-release
+			return io.NopCloser(strings.NewReader("slow")), nil
+		})
+	}()
+	<-started
+
+	reader, err := cache.Load("fast", load("fast"))
+	require.NoError(err)
+	actual, err := io.ReadAll(reader)
+	require.NoError(err)
+	require.Equal("fast", string(actual))
+
+	// Concurrent loads of the same key wait for (and share) the first load.
+	waiting := make(chan []byte)
+	go func() {
+		reader, err := cache.Load("slow", load("other"))
+		if err != nil {
+			waiting <- nil
+			return
+		}
+		actual, _ := io.ReadAll(reader)
+		waiting <- actual
+	}()
+	close(release)
+	require.Equal("slow", string(<-waiting))
+	<-slowDone
+	require.NoError(slowErr)
+	require.Equal(int32(1), calls.Load())
+}
@@ -0,0 +1,190 @@
+package jsonschema
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// DefaultRegistry is used by the package-level loader functions
+// and by any [Context] created without its own registry.
+var DefaultRegistry = NewDefaultRegistry()
+
+// NewRegistry returns a new, empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		loaders:  map[string]Loader{},
+		mappings: map[string]string{},
+	}
+}
+
+// NewDefaultRegistry returns a new Registry with loaders registered
+// for local files and HTTP(S) URIs. Archives and git revisions
+// must be registered explicitly with [Registry.RegisterArchive].
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.RegisterLoader(
+		NewCachedLoader(NewFileLoader()),
+		"", // missing schemes are assumed to be `file`.
+		"file",
+	)
+	r.RegisterLoader(
+		NewCachedLoader(NewHTTPLoader(http.DefaultClient)),
+		"http",
+		"https",
+	)
+	return r
+}
+
+// Registry is a set of loaders (keyed by URI scheme)
+// and the URI mappings applied before selecting one.
+type Registry struct {
+	loaders  map[string]Loader
+	mappings map[string]string
+	mu       sync.RWMutex
+}
+
+// Clone returns a copy of the registry.
+// Loaders are shared between the copies, but registering or
+// unregistering on one does not affect the other.
+func (r *Registry) Clone() *Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return &Registry{
+		loaders:  maps.Clone(r.loaders),
+		mappings: maps.Clone(r.mappings),
+	}
+}
+
+// Load loads the content from location using the appropriate Loader.
+// The location is rewritten using any registered URI mappings
+// before the loader is selected.
+func (r *Registry) Load(location string) (io.ReadCloser, error) {
+	u, err := url.Parse(r.MapURI(location))
+	if err != nil {
+		return nil, fmt.Errorf("parse location: %w", err)
+	}
+
+	loader := r.RegisteredLoader(u.Scheme)
+	if loader == nil {
+		return nil, fmt.Errorf("unknown scheme: %s", u.Scheme)
+	}
+	return loader.Load(u)
+}
+
+// RegisterLoader registers a loader for the given schemes.
+func (r *Registry) RegisterLoader(loader Loader, schemes ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, scheme := range schemes {
+		r.loaders[scheme] = loader
+	}
+}
+
+// RegisteredLoader returns the loader registered for a scheme.
+func (r *Registry) RegisteredLoader(scheme string) Loader {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.loaders[scheme]
+}
+
+// UnregisterLoader unregisters the loader for the given scheme.
+func (r *Registry) UnregisterLoader(scheme string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.loaders, scheme)
+}
+
+// RegisterArchive allows the archive referenced by uri (i.e. an input)
+// to be loaded from, registering the built-in loader for its scheme
+// (`zip`, `tar` or `git`) if needed. The built-in loaders only read
+// the archives registered this way (not any archive a ref points to).
+func (r *Registry) RegisterArchive(uri *url.URL) (*ArchiveLoader, error) {
+	location, _, err := splitArchivePath(uri.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	loader, ok := r.loaders[uri.Scheme].(*ArchiveLoader)
+	if !ok {
+		newLoader, ok := archiveLoaders[uri.Scheme]
+		if !ok {
+			return nil, fmt.Errorf("unknown archive scheme: %s", uri.Scheme)
+		}
+		loader = newLoader()
+		loader.allowed = map[string]bool{}
+		r.loaders[uri.Scheme] = loader
+	}
+	loader.Allow(location)
+	return loader, nil
+}
+
+// RegisterFS registers an [FSLoader] for fsys under scheme.
+// URIs beginning with any of the given base URIs are mapped
+// onto the root of fsys.
+func (r *Registry) RegisterFS(fsys fs.FS, scheme string, bases ...string) {
+	r.RegisterLoader(NewFSLoader(fsys), scheme)
+	for _, base := range bases {
+		r.RegisterMapping(base, scheme+":///")
+	}
+}
+
+// RegisterMapping rewrites URIs beginning with prefix to target.
+// Prefixes ending in a slash match every URI beneath them,
+// otherwise the prefix must match the URI exactly (ignoring any
+// query or fragment). Target may be a local path or another URI.
+func (r *Registry) RegisterMapping(prefix string, target string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mappings[prefix] = target
+}
+
+// RegisteredMapping returns the target registered for prefix.
+func (r *Registry) RegisteredMapping(prefix string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.mappings[prefix]
+}
+
+// UnregisterMapping unregisters the mapping for prefix.
+func (r *Registry) UnregisterMapping(prefix string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.mappings, prefix)
+}
+
+// MapURI rewrites location using the longest matching registered prefix.
+// Locations that do not match any prefix are returned unchanged.
+func (r *Registry) MapURI(location string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return mapURI(r.mappings, location)
+}
+
+// LoadCatalog loads the SchemaStore-style catalog located at location.
+func (r *Registry) LoadCatalog(location string) (*Catalog, error) {
+	reader, err := r.Load(location)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	catalog, err := parseCatalog(reader, location)
+	if err != nil {
+		return nil, err
+	}
+	catalog.readIDs(r.Load)
+	return catalog, nil
+}
+
+// RegisterCatalog registers the mappings for every entry in catalog.
+func (r *Registry) RegisterCatalog(catalog *Catalog) {
+	for prefix, target := range catalog.Mappings() {
+		r.RegisterMapping(prefix, target)
+	}
+}
@@ -0,0 +1,103 @@
+package jsonschema
+
+import (
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDefaultRegistry(t *testing.T) {
+	require := require.New(t)
+
+	registry := NewDefaultRegistry()
+	require.NotNil(registry.RegisteredLoader(""))
+	require.NotNil(registry.RegisteredLoader("file"))
+	require.NotNil(registry.RegisteredLoader("http"))
+	require.NotNil(registry.RegisteredLoader("https"))
+	require.NotSame(DefaultRegistry, registry)
+
+	// Archives (and git) must be registered explicitly.
+	require.Nil(registry.RegisteredLoader("zip"))
+	require.Nil(registry.RegisteredLoader("tar"))
+	require.Nil(registry.RegisteredLoader("git"))
+}
+
+func TestRegistry_Clone(t *testing.T) {
+	require := require.New(t)
+
+	loader := &mockLoader{}
+	registry := NewRegistry()
+	registry.RegisterLoader(loader, "foo")
+	registry.RegisterMapping("https://example.com/", "foo:///")
+
+	clone := registry.Clone()
+	require.Same(loader, clone.RegisteredLoader("foo"))
+	require.Equal("foo:///", clone.RegisteredMapping("https://example.com/"))
+
+	clone.UnregisterLoader("foo")
+	clone.UnregisterMapping("https://example.com/")
+	require.Same(loader, registry.RegisteredLoader("foo"))
+	require.Equal("foo:///", registry.RegisteredMapping("https://example.com/"))
+}
+
+func TestRegistry_Load(t *testing.T) {
+	require := require.New(t)
+
+	registry := NewRegistry()
+	registry.RegisterLoader(&mockLoader{
+		LoadFunc: func(uri *url.URL) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(uri.String())), nil
+		},
+	}, "foo")
+	registry.RegisterMapping("https://example.com/", "foo://bar/")
+
+	// Should error if location can not be parsed.
+	_, err := registry.Load("nope://some\nthing")
+	require.ErrorContains(err, "parse location:")
+
+	// Should error if unknown scheme (nothing registered by default).
+	_, err = registry.Load("file.json")
+	require.ErrorContains(err, "unknown scheme: ")
+
+	// Should map, then delegate to the registered loader.
+	reader, err := registry.Load("https://example.com/baz.json")
+	require.NoError(err)
+	actual, err := io.ReadAll(reader)
+	defer reader.Close()
+	require.NoError(err)
+	require.Equal("foo://bar/baz.json", string(actual))
+
+	// Should not affect the default registry.
+	require.Nil(RegisteredLoader("foo"))
+	require.Equal("", RegisteredMapping("https://example.com/"))
+}
+
+func TestRegistry_RegisterFS(t *testing.T) {
+	require := require.New(t)
+
+	fsys := fstest.MapFS{
+		"schemas/foo.json": &fstest.MapFile{Data: []byte(`{"title": "Foo"}`)},
+	}
+	registry := NewRegistry()
+	registry.RegisterFS(fsys, "embed", "https://example.com/")
+
+	for _, location := range []string{
+		"embed:///schemas/foo.json",
+		"embed://schemas/foo.json",
+		"https://example.com/schemas/foo.json",
+	} {
+		reader, err := registry.Load(location)
+		require.NoError(err, location)
+		actual, err := io.ReadAll(reader)
+		_ = reader.Close()
+		require.NoError(err)
+		require.Equal(`{"title": "Foo"}`, string(actual))
+	}
+
+	_, err := registry.Load("embed:///schemas/unknown.json")
+	require.Error(err)
+}
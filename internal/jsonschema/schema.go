@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"net/url"
 	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/gobuffalo/flect"
@@ -35,24 +37,24 @@ type Schema struct {
 	Enum                  []Any              `json:"enum,omitempty"`
 	EnumDescriptions      []string           `json:"enumDescriptions,omitempty"`
 	Examples              []Any              `json:"examples,omitempty"`
-	ExclusiveMaximum      float64            `json:"exclusiveMaximum,omitempty"`
-	ExclusiveMinimum      float64            `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum      *float64           `json:"exclusiveMaximum,omitempty"`
+	ExclusiveMinimum      *float64           `json:"exclusiveMinimum,omitempty"`
 	Format                string             `json:"format,omitempty"`
 	ID                    string             `json:"$id,omitempty"`
 	If                    *Schema            `json:"if,omitempty"`
 	Items                 *Schema            `json:"items,omitempty"`
 	MarkdownDescription   string             `json:"markdownDescription,omitempty"`
-	MaxContains           int                `json:"maxContains,omitempty"`
-	Maximum               float64            `json:"maximum,omitempty"`
-	MaxItems              int                `json:"maxItems,omitempty"`
-	MaxLength             int                `json:"maxLength,omitempty"`
-	MaxProperties         int                `json:"maxProperties,omitempty"`
-	MinContains           int                `json:"minContains,omitempty"`
-	Minimum               float64            `json:"minimum,omitempty"`
-	MinItems              int                `json:"minItems,omitempty"`
-	MinLength             int                `json:"minLength,omitempty"`
-	MinProperties         int                `json:"minProperties,omitempty"`
-	MultipleOf            float64            `json:"multipleOf,omitempty"`
+	MaxContains           *int               `json:"maxContains,omitempty"`
+	Maximum               *float64           `json:"maximum,omitempty"`
+	MaxItems              *int               `json:"maxItems,omitempty"`
+	MaxLength             *int               `json:"maxLength,omitempty"`
+	MaxProperties         *int               `json:"maxProperties,omitempty"`
+	MinContains           *int               `json:"minContains,omitempty"`
+	Minimum               *float64           `json:"minimum,omitempty"`
+	MinItems              *int               `json:"minItems,omitempty"`
+	MinLength             *int               `json:"minLength,omitempty"`
+	MinProperties         *int               `json:"minProperties,omitempty"`
+	MultipleOf            *float64           `json:"multipleOf,omitempty"`
 	Not                   []*Schema          `json:"not,omitempty"`
 	OneOf                 []*Schema          `json:"oneOf,omitempty"`
 	Pattern               string             `json:"pattern,omitempty"`
@@ -77,30 +79,37 @@ type Schema struct {
 
 	Context      *Context        `json:"-"`
 	Document     any             `json:"-"`
+	GenAnchor    string          `json:"-"` // Overrides the anchor derived from the entity name (i.e. to avoid collisions).
+	GenDir       string          `json:"-"` // Dir (relative to the output dir) to generate the root schema's pages in.
+	GenPathName  string          `json:"-"` // Overrides the path rendered from GenPathTpl (i.e. to avoid collisions).
 	GenPathTpl   render.Template `json:"-"`
 	Key          string          `json:"key,omitempty"`
+	Origin       *Schema         `json:"-"` // The $ref target this schema was resolved from.
 	Parent       *Schema         `json:"-"`
+	Pointer      string          `json:"-"` // JSON pointer to the schema within its document.
 	Resolved     bool            `json:"resolved,omitempty"`
 	RetrievalURI string          `json:"retrievalURI,omitempty"`
+	SourceDir    string          `json:"-"` // Dir that a local RetrievalURI is reported relative to (see SourceURI).
 }
 
 // BaseURI returns the resolved base URI for the schema.
 // The base URI is the schema $id attribute resolved against
 // the retrieval URI.
 func (s *Schema) BaseURI() *url.URL {
-	retURI, err := url.Parse(s.Root().RetrievalURI)
+	root := s.DocumentRoot()
+	retURI, err := url.Parse(root.RetrievalURI)
 	if err != nil {
 		panic(err)
 	}
 
-	if s.ID == "" {
+	if root.ID == "" {
 		// If the $id attribute is missing, then the base URI is assumed
 		// to be the same as the retrieval URI.
 		// See: https://json-schema.org/understanding-json-schema/structuring.html#retrieval-uri
 		return retURI
 	}
 
-	baseURI, err := url.Parse(s.Root().ID)
+	baseURI, err := url.Parse(root.ID)
 	if err != nil {
 		panic(err)
 	}
@@ -150,18 +159,158 @@ func (s *Schema) EntityName() string {
 	return ""
 }
 
+// EntityLink returns a link to the documentation for the entity.
+// Schemas resolved from a $ref link to the page of their canonical
+// [Schema.Entity] (which may be generated from another file).
+// The link is relative to the page the receiver is documented on,
+// and is just an anchor when both are documented on the same page
+// (e.g. when generating a single file).
 func (s *Schema) EntityLink() string {
+	entity := s.Entity()
+	source, target := s.Page().GenPath(), entity.GenPath()
+	if source == target {
+		return "#" + entity.EntityAnchor()
+	}
+	return RelativePath(source, target) + "#" + entity.EntityAnchor()
+}
+
+// EntityAnchor returns the anchor (i.e. heading ID) of the entity
+// within its page.
+func (s *Schema) EntityAnchor() string {
+	if s.GenAnchor != "" {
+		return s.GenAnchor
+	}
 	anchor := strings.ToLower(s.EntityName())
-	anchor = strings.ReplaceAll(anchor, " ", "-")
-	return s.GenPath() + "#" + anchor
+	return strings.ReplaceAll(anchor, " ", "-")
+}
+
+// Page returns the schema whose generated page documents the receiver:
+// the nearest root schema or definition (which may be the receiver).
+func (s *Schema) Page() *Schema {
+	if s.Parent == nil {
+		return s
+	}
+	if s.Key != "" && s.Parent.Definitions[s.Key] == s {
+		return s
+	}
+	return s.Parent.Page()
+}
+
+// Entity returns the canonical schema for the receiver.
+// For schemas resolved from a $ref, that is the (resolved) schema at
+// the ref target within its own document. Otherwise the receiver.
+func (s *Schema) Entity() *Schema {
+	entity := s
+	// Guard against ref cycles (i.e. `a` -> `b` -> `a`).
+	visited := map[*Schema]bool{}
+	for entity.Origin != nil && !visited[entity] {
+		visited[entity] = true
+		origin := entity.Origin
+		target := origin.Root().Lookup(origin.Pointer)
+		if target == nil || target == entity {
+			return origin
+		}
+		entity = target
+	}
+	return entity
+}
+
+// EntityURI returns the URI identifying the schema:
+// the base URI of its document and a JSON pointer fragment.
+func (s *Schema) EntityURI() string {
+	uri := *s.BaseURI()
+	uri.Fragment = s.Pointer
+	uri.RawFragment = ""
+	return uri.String()
+}
+
+// DocumentRoot returns the root of the document the schema is defined in.
+// This differs from [Schema.Root] for schemas resolved from a $ref to
+// another document, which are attached to the referencing schema.
+func (s *Schema) DocumentRoot() *Schema {
+	if s.Origin != nil {
+		return s.Origin.Root()
+	}
+	if s.Parent != nil {
+		return s.Parent.DocumentRoot()
+	}
+	return s
+}
+
+// SourceURI returns the URI (or path) the schema document was loaded from.
+// Local paths are relative to the SourceDir of the document (if any),
+// so that they do not depend on where the schemas are checked out.
+func (s *Schema) SourceURI() string {
+	root := s.DocumentRoot()
+	if root.SourceDir == "" || !filepath.IsAbs(root.RetrievalURI) {
+		return root.RetrievalURI
+	}
+	rel, err := filepath.Rel(root.SourceDir, root.RetrievalURI)
+	if err != nil {
+		return root.RetrievalURI
+	}
+	return filepath.ToSlash(rel)
 }
 
+// Lookup returns the sub-schema located at the given JSON pointer
+// (relative to the receiver), or nil if there is no such sub-schema.
+// Only definitions, properties, items, and oneOf are traversed.
+func (s *Schema) Lookup(pointer string) *Schema {
+	if pointer == "" || pointer == "/" {
+		return s
+	}
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for idx, token := range tokens {
+		token = strings.ReplaceAll(token, "~1", "/")
+		tokens[idx] = strings.ReplaceAll(token, "~0", "~")
+	}
+
+	current := s
+	for len(tokens) > 0 && current != nil {
+		switch tokens[0] {
+		case "items":
+			current = current.Items
+			tokens = tokens[1:]
+			continue
+		case "definitions", "properties", "oneOf":
+			if len(tokens) < 2 {
+				return nil
+			}
+		default:
+			return nil
+		}
+		switch tokens[0] {
+		case "definitions":
+			current = current.Definitions[tokens[1]]
+		case "properties":
+			current = current.Properties[tokens[1]]
+		default:
+			idx, err := strconv.Atoi(tokens[1])
+			if err != nil || idx < 0 || idx >= len(current.OneOf) {
+				return nil
+			}
+			current = current.OneOf[idx]
+		}
+		tokens = tokens[2:]
+	}
+	return current
+}
+
+// GenPath returns the path (relative to the output dir)
+// of the page documenting the schema.
 func (s *Schema) GenPath() string {
-	path, err := s.Root().GenPathTpl.Render(s)
+	if s.GenPathName != "" {
+		return s.GenPathName
+	}
+	root := s.Root()
+	rendered, err := root.GenPathTpl.Render(s)
 	if err != nil {
 		panic(err)
 	}
-	return path
+	if root.GenDir != "" {
+		return path.Join(root.GenDir, rendered)
+	}
+	return rendered
 }
 
 func (s *Schema) EnsureDocument() {
@@ -389,11 +538,17 @@ func (s *Schema) TypeInfo() []TypeInfo {
 }
 
 func (s *Schema) TypeInfoMarkdown() string {
+	return s.TypeInfoFormat(MarkdownLinkFormat, " &#124; ")
+}
+
+// TypeInfoFormat returns the type info formatted using the given
+// link format (see [TypeInfo.Format]), joined by separator.
+func (s *Schema) TypeInfoFormat(link string, separator string) string {
 	segments := []string{}
 	for _, ti := range s.TypeInfo() {
-		segments = append(segments, ti.Markdown())
+		segments = append(segments, ti.Format(link, separator))
 	}
-	return strings.Join(segments, " &#124; ")
+	return strings.Join(segments, separator)
 }
 
 type Type string
@@ -454,7 +609,17 @@ type TypeInfo struct {
 	Schema *Schema
 }
 
+// MarkdownLinkFormat is the [TypeInfo.Format] link format for Markdown.
+const MarkdownLinkFormat = "[%[1]s](%[2]s)"
+
 func (ti TypeInfo) Markdown() string {
+	return ti.Format(MarkdownLinkFormat, " &#124; ")
+}
+
+// Format returns the type formatted as text, with links to entities.
+// Link is a format string given the entity name (`%[1]s`)
+// and link (`%[2]s`). Separator separates the types of array items.
+func (ti TypeInfo) Format(link string, separator string) string {
 	if ti.Schema == nil {
 		return string(ti.Type)
 	}
@@ -468,16 +633,17 @@ func (ti TypeInfo) Markdown() string {
 			// string[]
 			// Widget[]
 			// etc...
-			return fmt.Sprintf("%s[]", ti.Schema.TypeInfoMarkdown())
+			return fmt.Sprintf("%s[]", ti.Schema.TypeInfoFormat(link, separator))
 		default:
 			// (string | number | Widget)[]
-			return fmt.Sprintf("(%s)[]", ti.Schema.TypeInfoMarkdown())
+			return fmt.Sprintf("(%s)[]", ti.Schema.TypeInfoFormat(link, separator))
 		}
 	case TypeObject:
-		if ti.Schema.EntityName() == "" {
+		entity := ti.Schema.Entity()
+		if entity.EntityName() == "" {
 			return string(ti.Type)
 		}
-		return fmt.Sprintf("[%s](%s)", ti.Schema.EntityName(), ti.Schema.EntityLink())
+		return fmt.Sprintf(link, entity.EntityName(), ti.Schema.EntityLink())
 	default:
 		return string(ti.Type)
 	}
@@ -495,6 +661,11 @@ func (a *Any) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, &a.value)
 }
 
+// Value returns the underlying (unmarshalled) value.
+func (a *Any) Value() any {
+	return a.value
+}
+
 func (a *Any) IsSet() bool {
 	return a.value != nil
 }
@@ -2,6 +2,7 @@ package jsonschema
 
 import (
 	"errors"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -115,6 +116,23 @@ func TestSchema_DescriptionMarkdown(t *testing.T) {
 	require.Equal("This **is** Markdown", schema.DescriptionMarkdown())
 }
 
+func TestSchema_Entity_WhenRefCycle(t *testing.T) {
+	require := require.New(t)
+
+	// `a` and `b` were each resolved from a ref to the other.
+	root := &Schema{Definitions: map[string]*Schema{}}
+	a := &Schema{Key: "a", Parent: root}
+	b := &Schema{Key: "b", Parent: root}
+	root.Definitions["a"] = a
+	root.Definitions["b"] = b
+	a.Origin = &Schema{Parent: root, Pointer: "/definitions/b"}
+	b.Origin = &Schema{Parent: root, Pointer: "/definitions/a"}
+
+	// Should stop at the first schema visited twice.
+	require.Same(a, a.Entity())
+	require.Same(b, b.Entity())
+}
+
 func TestSchema_EntityName(t *testing.T) {
 	require := require.New(t)
 
@@ -234,6 +252,15 @@ func TestSchema_GenPath(t *testing.T) {
 	}
 	require.Equal("my_schema.md", schema.GenPath())
 
+	schema = Schema{
+		GenDir:     "v1/sub",
+		GenPathTpl: *render.MustCompile(`{{ .Title | underscore }}.md`),
+		Title:      "MySchema",
+	}
+	require.Equal("v1/sub/my_schema.md", schema.GenPath())
+	child := Schema{Title: "Child", Parent: &schema}
+	require.Equal("v1/sub/child.md", child.GenPath())
+
 	schema = Schema{
 		GenPathTpl: *render.MustCompile(`{{ fail "boom" }}`),
 	}
@@ -256,8 +283,62 @@ func TestSchema_GenLink(t *testing.T) {
 		Parent: &schema1,
 	}
 
-	require.Equal("root_schema.md#rootschema", schema1.EntityLink()) // cspell: disable-line
-	require.Equal("root_schema.md#subschema", schema2.EntityLink())  // cspell: disable-line
+	// Links within the same file are just anchors.
+	require.Equal("#rootschema", schema1.EntityLink()) // cspell: disable-line
+	require.Equal("#subschema", schema2.EntityLink())  // cspell: disable-line
+}
+
+func TestSchema_EntityLink_CrossFile(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := filepath.Abs(filepath.Join("testdata", "shared"))
+	require.NoError(err)
+
+	context := NewContext()
+	a, err := context.Get(filepath.Join(dir, "a.schema.json"))
+	require.NoError(err)
+	common, err := context.Get(filepath.Join(dir, "common.json"))
+	require.NoError(err)
+
+	// Each document is generated to its own dir.
+	tpl := *render.MustCompile(`{{ .Root.Title | underscore }}/{{ .EntityName | underscore }}.md`)
+	a.GenPathTpl = tpl
+	common.GenPathTpl = tpl
+
+	// Links point to the page of the origin file, relative to the page
+	// the referencing schema is documented on.
+	home := a.Properties["home"]
+	require.Same(a, home.Page())
+	require.Equal("../common/address.md#address", home.EntityLink())
+	require.Equal("[Address](../common/address.md#address)", home.TypeInfoMarkdown())
+
+	work := a.Definitions["local"].Properties["work"]
+	require.Same(a.Definitions["local"], work.Page())
+	require.Equal("../common/address.md#address", work.EntityLink())
+
+	country := common.Definitions["address"].Properties["country"]
+	require.Equal("country.md#country", country.EntityLink())
+}
+
+func TestRelativePath(t *testing.T) {
+	tests := []struct {
+		source   string
+		target   string
+		expected string
+	}{
+		{"", "foo.md", "foo.md"},
+		{"foo.md", "", ""},
+		{"foo.md", "bar.md", "bar.md"},
+		{"a/foo.md", "a/bar.md", "bar.md"},
+		{"a/foo.md", "b/bar.md", "../b/bar.md"},
+		{"foo.md", "b/c/bar.md", "b/c/bar.md"},
+		{"a/b/foo.md", "bar.md", "../../bar.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source+"->"+tt.target, func(t *testing.T) {
+			require.Equal(t, tt.expected, RelativePath(tt.source, tt.target))
+		})
+	}
 }
 
 func TestSchema_Merge(t *testing.T) {
@@ -388,6 +469,66 @@ func TestSchema_Root(t *testing.T) {
 	require.Equal("schema1", schema3.Root().Title)
 }
 
+func TestSchema_DocumentRoot(t *testing.T) {
+	require := require.New(t)
+
+	other := &Schema{Title: "other"}
+	origin := &Schema{Title: "origin", Parent: other}
+	root := &Schema{Title: "root"}
+	resolved := &Schema{Title: "resolved", Parent: root, Origin: origin}
+	child := &Schema{Title: "child", Parent: resolved}
+
+	require.Same(root, root.DocumentRoot())
+	require.Same(other, resolved.DocumentRoot())
+	require.Same(other, child.DocumentRoot())
+	require.Same(root, child.Root())
+}
+
+func TestSchema_SourceURI(t *testing.T) {
+	require := require.New(t)
+	dir := filepath.Join(string(filepath.Separator), "src", "schemas")
+
+	root := &Schema{RetrievalURI: filepath.Join(dir, "v1", "widget.json")}
+	child := &Schema{Parent: root}
+	require.Equal(root.RetrievalURI, child.SourceURI())
+
+	// Local paths are relative to the source dir.
+	root.SourceDir = dir
+	require.Equal("v1/widget.json", child.SourceURI())
+	root.SourceDir = filepath.Join(dir, "v2")
+	require.Equal("../v1/widget.json", child.SourceURI())
+
+	// URIs are kept as is.
+	root.RetrievalURI = "https://example.com/widget.json"
+	require.Equal("https://example.com/widget.json", child.SourceURI())
+}
+
+func TestSchema_Lookup(t *testing.T) {
+	require := require.New(t)
+
+	item := &Schema{Title: "item"}
+	variant := &Schema{Title: "variant"}
+	slashed := &Schema{Title: "slashed"}
+	schema := &Schema{
+		Definitions: map[string]*Schema{
+			"list": {Items: item},
+			"a/b":  slashed,
+		},
+		Properties: map[string]*Schema{
+			"choice": {OneOf: []*Schema{variant}},
+		},
+	}
+
+	require.Same(schema, schema.Lookup(""))
+	require.Same(item, schema.Lookup("/definitions/list/items"))
+	require.Same(slashed, schema.Lookup("/definitions/a~1b"))
+	require.Same(variant, schema.Lookup("/properties/choice/oneOf/0"))
+	require.Nil(schema.Lookup("/properties/choice/oneOf/1"))
+	require.Nil(schema.Lookup("/properties/unknown"))
+	require.Nil(schema.Lookup("/definitions"))
+	require.Nil(schema.Lookup("/allOf/0"))
+}
+
 func TestSchema_TypeInfo(t *testing.T) {
 	require := require.New(t)
 
@@ -487,6 +628,17 @@ func TestTypeInfo_Markdown(t *testing.T) {
 		},
 	}
 	require.Equal("[Schema2](#schema2)", ti.Markdown())
+	require.Equal("xref:#schema2[Schema2]", ti.Format("xref:%[2]s[%[1]s]", " | "))
+
+	schema := &Schema{
+		Types: []Type{TypeArray},
+		Items: &Schema{
+			Types: []Type{TypeString, TypeObject},
+			Title: "Schema3",
+		},
+	}
+	require.Equal("(string &#124; [Schema3](#schema3))[]", schema.TypeInfoMarkdown())
+	require.Equal("(string | `Schema3 <#schema3>`_)[]", schema.TypeInfoFormat("`%[1]s <%[2]s>`_", " | "))
 }
 
 func TestAny_String(t *testing.T) {
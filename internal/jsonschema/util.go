@@ -2,6 +2,8 @@ package jsonschema
 
 import (
 	"fmt"
+	"path"
+	"path/filepath"
 	"strings"
 )
 
@@ -13,3 +15,17 @@ func WordSequence(words []string, separator string) string {
 	wordSeq := strings.Join(words[:len(words)-1], ", ")
 	return fmt.Sprintf("%s, %s %s", wordSeq, separator, lastWord)
 }
+
+// RelativePath returns the slash-separated path to target
+// relative to the dir containing source.
+// Both paths are relative to the same (output) dir.
+func RelativePath(source string, target string) string {
+	if source == "" || target == "" {
+		return target
+	}
+	rel, err := filepath.Rel(filepath.FromSlash(path.Dir(source)), filepath.FromSlash(target))
+	if err != nil {
+		return target
+	}
+	return filepath.ToSlash(rel)
+}
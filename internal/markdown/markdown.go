@@ -6,16 +6,19 @@ import (
 	"strings"
 	"unicode"
 
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
 	"github.com/tdewolff/minify/v2"
 	mhtml "github.com/tdewolff/minify/v2/html"
 	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
 	"github.com/yuin/goldmark/renderer/html"
 )
 
-func newConverter() goldmark.Markdown {
-	return goldmark.New(
+func newConverter(opts ...goldmark.Option) goldmark.Markdown {
+	opts = append([]goldmark.Option{
 		goldmark.WithExtensions(
 			extension.GFM,
 			extension.Footnote,
@@ -27,7 +30,8 @@ func newConverter() goldmark.Markdown {
 			// html.WithHardWraps(),
 			html.WithXHTML(),
 		),
-	)
+	}, opts...)
+	return goldmark.New(opts...)
 }
 
 func newMinifier() *minify.M {
@@ -56,6 +60,29 @@ func ToHTMLBytes(markdown []byte) ([]byte, error) {
 	return b, nil
 }
 
+// ToHTMLDocument converts the given Markdown document to HTML.
+// Unlike [ToHTMLBytes], the output is not minified (so that code blocks
+// keep their line breaks) and raw HTML in the document is kept once
+// sanitized (see [SanitizeHTML]), so that the HTML generated by templates
+// survives, but schema descriptions can not inject scripts.
+// Code blocks are syntax highlighted using CSS classes
+// (see the stylesheet in the jsonschema templates).
+func ToHTMLDocument(markdown []byte) ([]byte, error) {
+	converted := &bytes.Buffer{}
+	converter := newConverter(
+		goldmark.WithExtensions(
+			highlighting.NewHighlighting(
+				highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+			),
+		),
+		goldmark.WithRendererOptions(renderer.WithNodeRenderers(newSanitizingRenderer())),
+	)
+	if err := converter.Convert(markdown, converted); err != nil {
+		return nil, fmt.Errorf("convert error: %w", err)
+	}
+	return converted.Bytes(), nil
+}
+
 func ToHTMLString(markdown string) (string, error) {
 	buf, err := ToHTMLBytes([]byte(markdown))
 	return string(buf), err
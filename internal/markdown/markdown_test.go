@@ -29,6 +29,19 @@ func TestToHTMLBytes(t *testing.T) {
 	g.Assert(t, filename, []byte(htmlString))
 }
 
+func TestToHTMLDocument(t *testing.T) {
+	require := require.New(t)
+
+	content, err := os.ReadFile("testdata/document.md")
+	require.NoError(err)
+
+	htmlBytes, err := ToHTMLDocument(content)
+	require.NoError(err)
+
+	g := goldie.New(t)
+	g.Assert(t, "document", htmlBytes)
+}
+
 func TestWrapCode(t *testing.T) {
 	require := require.New(t)
 
@@ -45,3 +58,44 @@ func TestFirstSentence(t *testing.T) {
 	require.Equal("Hello there.", FirstSentence("Hello there. How are you?"))
 	require.Equal("How are you?", FirstSentence("How are you?"))
 }
+
+func TestToHTMLDocument_WhenUnsafeHTML(t *testing.T) {
+	require := require.New(t)
+
+	content := []byte(`Hello <em onclick="alert(1)">there</em><script>alert(1)</script>
+
+<div class="x"><img src="javascript:alert(1)" onerror="alert(1)"></div>
+
+| Name | Description |
+| ---- | ----------- |
+| foo | <p>The <code>foo</code> <a href="https://example.com">link</a>.</p> |
+`)
+	htmlBytes, err := ToHTMLDocument(content)
+	require.NoError(err)
+	htmlString := string(htmlBytes)
+
+	require.Contains(htmlString, `<em>there</em>&lt;script&gt;alert(1)&lt;/script&gt;`)
+	require.Contains(htmlString, `<div class="x"><img></div>`)
+	require.Contains(htmlString, `<p>The <code>foo</code> <a href="https://example.com">link</a>.</p>`)
+	require.NotContains(htmlString, "<script")
+	require.NotContains(htmlString, "onerror")
+}
+
+func TestSanitizeHTML(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{`<p>ok</p>`, `<p>ok</p>`},
+		{`<BR/>`, `<br />`},
+		{`<a href='#foo' title="a &amp; b" target=_blank>x</a>`, `<a href="#foo" title="a &amp; b">x</a>`},
+		{`<a href="java&#x09;script:alert(1)">x</a>`, `<a>x</a>`},
+		{`<a href="MAILTO:me@example.com">x</a>`, `<a href="MAILTO:me@example.com">x</a>`},
+		{`<style>p {}</style>`, `&lt;style&gt;p {}&lt;/style&gt;`},
+		{`<!-- comment -->text`, `text`},
+		{`1 < 2 &amp; 3 > 2`, `1 &lt; 2 &amp; 3 &gt; 2`},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, string(SanitizeHTML([]byte(tt.raw))), tt.raw)
+	}
+}
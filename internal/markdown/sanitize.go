@@ -0,0 +1,166 @@
+package markdown
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// allowedTags are the elements kept when sanitizing raw HTML:
+// those produced by converting Markdown to HTML (i.e. by `toHTML`),
+// plus a few common formatting elements. Other tags are escaped.
+var allowedTags = map[string]bool{
+	"a": true, "abbr": true, "b": true, "blockquote": true, "br": true,
+	"code": true, "dd": true, "del": true, "details": true, "div": true,
+	"dl": true, "dt": true, "em": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "hr": true, "i": true, "img": true,
+	"kbd": true, "li": true, "ol": true, "p": true, "pre": true, "s": true,
+	"span": true, "strong": true, "sub": true, "summary": true, "sup": true,
+	"table": true, "tbody": true, "td": true, "th": true, "thead": true,
+	"tr": true, "u": true, "ul": true,
+}
+
+// allowedAttrs are the attributes kept on allowed tags.
+var allowedAttrs = map[string]bool{
+	"align": true, "alt": true, "class": true, "colspan": true, "href": true,
+	"id": true, "rowspan": true, "src": true, "start": true, "title": true,
+}
+
+// allowedSchemes are the URL schemes allowed in `href` and `src` attributes
+// (relative URLs are always allowed).
+var allowedSchemes = map[string]bool{
+	"http": true, "https": true, "mailto": true,
+}
+
+var (
+	commentRegexp = regexp.MustCompile(`(?s)<!--.*?-->`)
+	tagRegexp     = regexp.MustCompile(
+		`<(/?)([A-Za-z][A-Za-z0-9]*)((?:\s+[^\s"'>/=]+(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s"'=<>` + "`" + `]+))?)*)\s*(/?)>`,
+	)
+	attrRegexp = regexp.MustCompile(
+		`([^\s"'>/=]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'=<>` + "`" + `]+)))?`,
+	)
+	schemeRegexp = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9+.-]*):`)
+)
+
+// SanitizeHTML returns raw with the allowed tags (and attributes) kept,
+// comments removed, and everything else escaped.
+func SanitizeHTML(raw []byte) []byte {
+	raw = commentRegexp.ReplaceAll(raw, nil)
+	buf := &bytes.Buffer{}
+	last := 0
+	for _, match := range tagRegexp.FindAllSubmatchIndex(raw, -1) {
+		writeEscapedText(buf, raw[last:match[0]])
+		last = match[1]
+
+		closing := match[3] > match[2]
+		name := strings.ToLower(string(raw[match[4]:match[5]]))
+		if !allowedTags[name] {
+			buf.WriteString(html.EscapeString(string(raw[match[0]:match[1]])))
+			continue
+		}
+		buf.WriteString("<")
+		if closing {
+			buf.WriteString("/")
+		}
+		buf.WriteString(name)
+		if !closing {
+			writeAttrs(buf, string(raw[match[6]:match[7]]))
+		}
+		if match[9] > match[8] {
+			buf.WriteString(" /")
+		}
+		buf.WriteString(">")
+	}
+	writeEscapedText(buf, raw[last:])
+	return buf.Bytes()
+}
+
+// writeAttrs writes the allowed attributes in attrs (quoted and escaped).
+func writeAttrs(buf *bytes.Buffer, attrs string) {
+	for _, attr := range attrRegexp.FindAllStringSubmatch(attrs, -1) {
+		name := strings.ToLower(attr[1])
+		if !allowedAttrs[name] {
+			continue
+		}
+		value := html.UnescapeString(attr[2] + attr[3] + attr[4])
+		if (name == "href" || name == "src") && !isAllowedURL(value) {
+			continue
+		}
+		buf.WriteString(" " + name + `="` + html.EscapeString(value) + `"`)
+	}
+}
+
+// isAllowedURL returns true if url is relative or has an allowed scheme.
+func isAllowedURL(url string) bool {
+	// Browsers ignore whitespace and control chars (i.e. `java\tscript:`).
+	url = strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, url)
+	match := schemeRegexp.FindStringSubmatch(url)
+	return match == nil || allowedSchemes[strings.ToLower(match[1])]
+}
+
+// writeEscapedText writes text (outside of tags) with any stray
+// angle brackets escaped. Entities are kept as is.
+func writeEscapedText(buf *bytes.Buffer, text []byte) {
+	text = bytes.ReplaceAll(text, []byte("<"), []byte("&lt;"))
+	text = bytes.ReplaceAll(text, []byte(">"), []byte("&gt;"))
+	buf.Write(text)
+}
+
+// sanitizingRenderer renders raw HTML (inline and blocks) sanitized
+// by [SanitizeHTML], rather than verbatim (or omitted).
+type sanitizingRenderer struct{}
+
+// newSanitizingRenderer returns the renderer, prioritized
+// over the default raw HTML rendering.
+func newSanitizingRenderer() util.PrioritizedValue {
+	return util.Prioritized(&sanitizingRenderer{}, 100)
+}
+
+func (r *sanitizingRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindRawHTML, r.renderRawHTML)
+	reg.Register(ast.KindHTMLBlock, r.renderHTMLBlock)
+}
+
+func (r *sanitizingRenderer) renderRawHTML(
+	w util.BufWriter, source []byte, node ast.Node, entering bool,
+) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkSkipChildren, nil
+	}
+	n := node.(*ast.RawHTML)
+	raw := []byte{}
+	for i := range n.Segments.Len() {
+		segment := n.Segments.At(i)
+		raw = append(raw, segment.Value(source)...)
+	}
+	_, _ = w.Write(SanitizeHTML(raw))
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *sanitizingRenderer) renderHTMLBlock(
+	w util.BufWriter, source []byte, node ast.Node, entering bool,
+) (ast.WalkStatus, error) {
+	n := node.(*ast.HTMLBlock)
+	raw := []byte{}
+	if entering {
+		for i := range n.Lines().Len() {
+			line := n.Lines().At(i)
+			raw = append(raw, line.Value(source)...)
+		}
+	} else if n.HasClosure() {
+		raw = n.ClosureLine.Value(source)
+	}
+	_, _ = w.Write(SanitizeHTML(raw))
+	return ast.WalkContinue, nil
+}
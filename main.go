@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/twelvelabs/schemadoc/internal/cmd"
@@ -19,18 +20,25 @@ var (
 func run() error {
 	path, err := core.ConfigPath(os.Args)
 	if err != nil {
-		return err
+		return reportErr(err)
 	}
 
 	app, err := core.NewApp(version, commit, date, path)
 	if err != nil {
-		return err
+		return reportErr(err)
 	}
 	defer app.Close()
 
 	return cmd.NewRootCmd(app).ExecuteContext(app.Context())
 }
 
+// reportErr prints err (as cobra does for command errors),
+// since it occurred before the commands could run.
+func reportErr(err error) error {
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	return err
+}
+
 func main() {
 	if err := run(); err != nil {
 		os.Exit(1)